@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +16,55 @@ type Config struct {
 	SupabaseURL        string
 	SupabaseKey        string
 	SupabaseServiceKey string
+
+	// Ürün görsellerinin yüklendiği storage bucket'ı
+	ProductImagesBucket string
+
+	// Dosya depolama: "supabase", "local" veya "s3"
+	StorageDriver       string
+	LocalStorageDir     string
+	LocalStorageBaseURL string
+	S3Endpoint          string
+	S3Region            string
+	S3AccessKeyID       string
+	S3SecretAccessKey   string
+	S3UsePathStyle      bool
+	S3PublicURL         string
+
+	// Terk edilmiş sepet hatırlatmaları
+	AbandonedCartEnabled       bool
+	AbandonedCartIdleAfter     time.Duration
+	AbandonedCartCheckInterval time.Duration
+	AbandonedCartMaxReminders  int
+
+	// Sepet stok tutma (soft hold)
+	CartHoldsEnabled        bool
+	CartHoldTTL             time.Duration
+	CartHoldCleanupInterval time.Duration
+
+	// Bildirim kanalı: "log" veya "webhook"
+	Notifier           string
+	NotifierWebhookURL string
+
+	// Ürün feed'leri (Google Merchant RSS/TSV, JSON katalog)
+	FeedRefreshInterval time.Duration
+	FeedTitle           string
+	FeedBaseURL         string // mağaza (frontend) adresi; ürün linkleri buna göre üretilir
+	FeedImageBaseURL    string // göreli görsel yollarının (ör: /uploads/...) önüne eklenir
+	FeedProductPath     string // {id}, {sku} ve {slug} yer tutucularını içeren ürün sayfası yolu
+	FeedCategoryPath    string // {slug} yer tutuculu kategori sayfası yolu (sitemap)
+	FeedCurrency        string
+	FeedFieldMappings   string // "alan:kaynak" listesi, ör: "brand:=Acme,mpn:sku"
+
+	// İlgili ürün önerileri (periyodik hesaplanır; interval <= 0 ise job çalışmaz)
+	RecommendationsRefreshInterval time.Duration
+	RecommendationsLookback        time.Duration // birlikte satın alma için bakılan sipariş geçmişi
+	RecommendationsPerProduct      int
+
+	// Ürün görüntüleme takibi
+	ProductViewDebounce        time.Duration // aynı görüntüleyenin tekrar sayılmadığı süre
+	ProductViewRetention       time.Duration // "son baktıklarım" kayıtlarının saklanma süresi
+	ProductViewCleanupInterval time.Duration
 }
 
 func Load() *Config {
@@ -29,6 +80,47 @@ func Load() *Config {
 		SupabaseURL:        getEnv("SUPABASE_URL", ""),
 		SupabaseKey:        getEnv("SUPABASE_ANON_KEY", ""),
 		SupabaseServiceKey: getEnv("SUPABASE_SERVICE_ROLE_KEY", ""),
+
+		ProductImagesBucket: getEnv("PRODUCT_IMAGES_BUCKET", "products"),
+
+		StorageDriver:       getEnv("STORAGE_DRIVER", "supabase"),
+		LocalStorageDir:     getEnv("LOCAL_STORAGE_DIR", "./uploads"),
+		LocalStorageBaseURL: getEnv("LOCAL_STORAGE_BASE_URL", "/uploads"),
+		S3Endpoint:          getEnv("S3_ENDPOINT", ""),
+		S3Region:            getEnv("S3_REGION", "us-east-1"),
+		S3AccessKeyID:       getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey:   getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3UsePathStyle:      getEnvBool("S3_USE_PATH_STYLE", true),
+		S3PublicURL:         getEnv("S3_PUBLIC_URL", ""),
+
+		AbandonedCartEnabled:       getEnvBool("ABANDONED_CART_ENABLED", false),
+		AbandonedCartIdleAfter:     getEnvDuration("ABANDONED_CART_IDLE_AFTER", 24*time.Hour),
+		AbandonedCartCheckInterval: getEnvDuration("ABANDONED_CART_CHECK_INTERVAL", 15*time.Minute),
+		AbandonedCartMaxReminders:  getEnvInt("ABANDONED_CART_MAX_REMINDERS", 2),
+
+		CartHoldsEnabled:        getEnvBool("CART_HOLDS_ENABLED", false),
+		CartHoldTTL:             getEnvDuration("CART_HOLD_TTL", 15*time.Minute),
+		CartHoldCleanupInterval: getEnvDuration("CART_HOLD_CLEANUP_INTERVAL", time.Minute),
+
+		Notifier:           getEnv("NOTIFIER", "log"),
+		NotifierWebhookURL: getEnv("NOTIFIER_WEBHOOK_URL", ""),
+
+		FeedRefreshInterval: getEnvDuration("FEED_REFRESH_INTERVAL", time.Hour),
+		FeedTitle:           getEnv("FEED_TITLE", "Ürün Kataloğu"),
+		FeedBaseURL:         getEnv("FEED_BASE_URL", "http://localhost:3000"),
+		FeedImageBaseURL:    getEnv("FEED_IMAGE_BASE_URL", "http://localhost:8080"),
+		FeedProductPath:     getEnv("FEED_PRODUCT_PATH", "/products/{slug}"),
+		FeedCategoryPath:    getEnv("FEED_CATEGORY_PATH", "/categories/{slug}"),
+		FeedCurrency:        getEnv("FEED_CURRENCY", "TRY"),
+		FeedFieldMappings:   getEnv("FEED_FIELD_MAPPINGS", ""),
+
+		RecommendationsRefreshInterval: getEnvDuration("RECOMMENDATIONS_REFRESH_INTERVAL", 6*time.Hour),
+		RecommendationsLookback:        getEnvDuration("RECOMMENDATIONS_LOOKBACK", 365*24*time.Hour),
+		RecommendationsPerProduct:      getEnvInt("RECOMMENDATIONS_PER_PRODUCT", 20),
+
+		ProductViewDebounce:        getEnvDuration("PRODUCT_VIEW_DEBOUNCE", 30*time.Minute),
+		ProductViewRetention:       getEnvDuration("PRODUCT_VIEW_RETENTION", 90*24*time.Hour),
+		ProductViewCleanupInterval: getEnvDuration("PRODUCT_VIEW_CLEANUP_INTERVAL", time.Hour),
 	}
 }
 
@@ -38,3 +130,33 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid integer for %s, using default %d", key, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid boolean for %s, using default %t", key, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid duration for %s, using default %s", key, defaultValue)
+	}
+	return defaultValue
+}
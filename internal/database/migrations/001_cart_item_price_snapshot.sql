@@ -0,0 +1,7 @@
+-- Sepete eklendiği andaki ürün fiyatı (fiyat değişikliği uyarıları için)
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS price_at_add NUMERIC(12, 2);
+
+UPDATE cart_items ci
+SET price_at_add = p.price
+FROM products p
+WHERE ci.product_id = p.id AND ci.price_at_add IS NULL;
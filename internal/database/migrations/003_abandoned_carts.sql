@@ -0,0 +1,17 @@
+-- Terk edilmiş sepet olayları ve hatırlatma tercihi
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS cart_reminder_opt_out BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS cart_abandonment_events (
+    id SERIAL PRIMARY KEY,
+    cart_id INTEGER NOT NULL REFERENCES carts (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    reminder_number INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_abandonment_events_cart ON cart_abandonment_events (cart_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_carts_updated_at ON carts (updated_at);
+
+UPDATE carts SET updated_at = created_at WHERE updated_at IS NULL;
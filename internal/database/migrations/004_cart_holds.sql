@@ -0,0 +1,13 @@
+-- Sepete eklenen ürünler için süreli (soft) stok tutma kayıtları
+CREATE TABLE IF NOT EXISTS cart_holds (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_holds_product_active ON cart_holds (product_id, expires_at);
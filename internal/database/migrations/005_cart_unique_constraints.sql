@@ -0,0 +1,38 @@
+-- Eşzamanlı isteklerde mükerrer sepet / sepet satırı oluşmasını engelle.
+-- Önce mevcut mükerrer kayıtlar kullanıcının en eski sepetinde birleştirilir.
+
+-- Mükerrer sepetlerdeki satırları en eski sepete taşı
+WITH keeper AS (
+    SELECT user_id, MIN(id) AS keep_id
+    FROM carts
+    GROUP BY user_id
+    HAVING COUNT(*) > 1
+)
+UPDATE cart_items ci
+SET cart_id = k.keep_id
+FROM carts ca
+JOIN keeper k ON k.user_id = ca.user_id
+WHERE ci.cart_id = ca.id AND ca.id <> k.keep_id;
+
+DELETE FROM carts ca
+USING carts older
+WHERE ca.user_id = older.user_id AND ca.id > older.id;
+
+-- Aynı ürün için birden fazla satırı tek satırda topla
+WITH merged AS (
+    SELECT cart_id, product_id, MIN(id) AS keep_id, SUM(quantity) AS total_quantity
+    FROM cart_items
+    GROUP BY cart_id, product_id
+    HAVING COUNT(*) > 1
+)
+UPDATE cart_items ci
+SET quantity = m.total_quantity
+FROM merged m
+WHERE ci.id = m.keep_id;
+
+DELETE FROM cart_items ci
+USING cart_items older
+WHERE ci.cart_id = older.cart_id AND ci.product_id = older.product_id AND ci.id > older.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_carts_user_id_unique ON carts (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_cart_product_unique ON cart_items (cart_id, product_id);
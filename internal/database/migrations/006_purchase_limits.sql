@@ -0,0 +1,8 @@
+-- Ürün bazlı satın alma limitleri (NULL = limit yok)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS max_per_order INTEGER CHECK (max_per_order > 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS max_per_customer INTEGER CHECK (max_per_customer > 0);
+-- max_per_customer için geriye dönük pencere (gün); NULL ise tüm siparişler sayılır
+ALTER TABLE products ADD COLUMN IF NOT EXISTS purchase_limit_window_days INTEGER CHECK (purchase_limit_window_days > 0);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items (product_id);
+CREATE INDEX IF NOT EXISTS idx_orders_user_created ON orders (user_id, created_at);
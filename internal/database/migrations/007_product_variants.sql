@@ -0,0 +1,59 @@
+-- Ürün seçenekleri (beden, renk...) ve kendi SKU / fiyat / stoku olan varyantlar
+CREATE TABLE IF NOT EXISTS product_options (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (product_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_option_values (
+    id SERIAL PRIMARY KEY,
+    option_id INTEGER NOT NULL REFERENCES product_options (id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (option_id, value)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku TEXT NOT NULL UNIQUE,
+    price NUMERIC(12, 2),
+    image TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product ON product_variants (product_id);
+
+CREATE TABLE IF NOT EXISTS product_variant_options (
+    variant_id INTEGER NOT NULL REFERENCES product_variants (id) ON DELETE CASCADE,
+    option_value_id INTEGER NOT NULL REFERENCES product_option_values (id) ON DELETE CASCADE,
+    PRIMARY KEY (variant_id, option_value_id)
+);
+
+-- inventory tablosuyla aynı yapı; stok sorgularında "i" alias'ı ile kullanılabilir
+CREATE TABLE IF NOT EXISTS variant_inventory (
+    id SERIAL PRIMARY KEY,
+    variant_id INTEGER NOT NULL UNIQUE REFERENCES product_variants (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    reserved_quantity INTEGER NOT NULL DEFAULT 0,
+    min_stock_level INTEGER NOT NULL DEFAULT 0,
+    max_stock_level INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Varyant bilgisi sepet satırları, sipariş öğeleri ve stok hold'larına taşınır
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants (id) ON DELETE CASCADE;
+DROP INDEX IF EXISTS idx_cart_items_cart_product_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_cart_product_variant_unique
+    ON cart_items (cart_id, product_id, (COALESCE(variant_id, 0)));
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants (id) ON DELETE SET NULL;
+
+ALTER TABLE cart_holds ADD COLUMN IF NOT EXISTS variant_id INTEGER REFERENCES product_variants (id) ON DELETE CASCADE;
+ALTER TABLE cart_holds DROP CONSTRAINT IF EXISTS cart_holds_user_id_product_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_holds_user_product_variant
+    ON cart_holds (user_id, product_id, (COALESCE(variant_id, 0)));
@@ -0,0 +1,18 @@
+-- Ürün görsel galerisi; dosyalar storage'daki products bucket'ında tutulur.
+-- Birincil görselin URL'i geriye uyumluluk için products.image alanına da yazılır.
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    object_path TEXT NOT NULL,
+    url TEXT NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product ON product_images (product_id, position);
+
+-- Ürün başına en fazla bir birincil görsel
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_primary
+    ON product_images (product_id) WHERE is_primary;
@@ -0,0 +1,7 @@
+-- İşlenmiş görsel varyantları (thumbnail, medium, large, webp): isim -> URL
+-- ve storage temizliği için yüklenen tüm nesne yolları
+ALTER TABLE product_images ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE product_images ADD COLUMN IF NOT EXISTS variant_paths TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS avatar_variants JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS avatar_paths TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,54 @@
+-- Hiyerarşik kategori ağacı. products.category metin alanı geriye uyumluluk
+-- için korunur ve kategori adıyla senkron tutulur; filtreleme category_id üzerinden yapılır.
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INTEGER REFERENCES categories (id) ON DELETE RESTRICT,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    image TEXT NOT NULL DEFAULT '',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT categories_not_own_parent CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug);
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories (parent_id, sort_order);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES categories (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
+
+-- Mevcut kategori metinlerinden kök kategoriler oluştur. Slug: Türkçe karakterler
+-- ASCII karşılıklarına çevrilir, harf/rakam dışı karakterler tireye dönüşür.
+INSERT INTO categories (name, slug)
+SELECT DISTINCT ON (slug) name, slug
+FROM (
+    SELECT TRIM(category) AS name,
+           TRIM(BOTH '-' FROM REGEXP_REPLACE(
+               LOWER(TRANSLATE(TRIM(category), 'İIÇĞÖŞÜÂÎÛçğıöşüâîû', 'iicgosuaiucgiosuaiu')),
+               '[^a-z0-9]+', '-', 'g'
+           )) AS slug
+    FROM products
+    WHERE category IS NOT NULL AND TRIM(category) <> ''
+) legacy
+WHERE slug <> ''
+ORDER BY slug, name
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE products p
+SET category_id = c.id
+FROM categories c
+WHERE p.category_id IS NULL
+  AND p.category IS NOT NULL
+  AND c.slug = TRIM(BOTH '-' FROM REGEXP_REPLACE(
+      LOWER(TRANSLATE(TRIM(p.category), 'İIÇĞÖŞÜÂÎÛçğıöşüâîû', 'iicgosuaiucgiosuaiu')),
+      '[^a-z0-9]+', '-', 'g'
+  ));
+
+-- Ürünlerin kategori metni, eşleştiği kategorinin adıyla aynı hale getirilir
+UPDATE products p
+SET category = c.name
+FROM categories c
+WHERE p.category_id = c.id AND p.category IS DISTINCT FROM c.name;
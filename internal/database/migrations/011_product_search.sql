@@ -0,0 +1,24 @@
+-- Ürün araması için tam metin indeksi. turkish_unaccent konfigürasyonu önce
+-- aksan/Türkçe karakterleri sadeleştirir (ı, İ, ş, ğ...), sonra Türkçe kök bulur;
+-- böylece "isik" araması "Işık" ile eşleşir.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'turkish_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION turkish_unaccent (COPY = turkish);
+        ALTER TEXT SEARCH CONFIGURATION turkish_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, turkish_stem;
+    END IF;
+END
+$$;
+
+-- Ağırlıklar: başlık (A) > açıklama (B) > kategori (C)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('turkish_unaccent'::regconfig, COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('turkish_unaccent'::regconfig, COALESCE(description, '')), 'B') ||
+        setweight(to_tsvector('turkish_unaccent'::regconfig, COALESCE(category, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
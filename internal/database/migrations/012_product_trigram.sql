@@ -0,0 +1,19 @@
+-- Yazım hatalarına toleranslı arama ve öneriler için trigram indeksleri.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- search_normalize aksanları ve Türkçe karakterleri sadeleştirip küçük harfe çevirir
+-- (İ/ı -> i, ş -> s...). unaccent STABLE olduğundan indekslenebilmesi için sözlük
+-- açıkça verilerek IMMUTABLE sarmalayıcı tanımlanır.
+CREATE OR REPLACE FUNCTION search_normalize(value TEXT) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+AS $$
+    SELECT LOWER(public.unaccent('public.unaccent'::regdictionary, value))
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_products_title_trgm
+    ON products USING GIN (search_normalize(title) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_sku_trgm
+    ON products USING GIN (search_normalize(COALESCE(sku, '')) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_categories_name_trgm
+    ON categories USING GIN (search_normalize(name) gin_trgm_ops);
@@ -0,0 +1,8 @@
+-- Ürün arşivleme (soft delete). deleted_at dolu ürünler katalogda görünmez ve satın
+-- alınamaz; sepet, istek listesi ve sipariş geçmişinde ise kaydı korunur.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_products_live
+    ON products (created_at DESC) WHERE deleted_at IS NULL AND is_active = true;
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at
+    ON products (deleted_at) WHERE deleted_at IS NOT NULL;
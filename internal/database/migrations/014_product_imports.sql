@@ -0,0 +1,26 @@
+-- Toplu ürün içe aktarma işleri (CSV / JSON lines): ilerleme ve satır bazlı hata raporu
+CREATE TABLE IF NOT EXISTS product_imports (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    format TEXT NOT NULL CHECK (format IN ('csv', 'jsonl')),
+    file_name TEXT NOT NULL DEFAULT '',
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    -- [{"row": 12, "sku": "ABC-1", "error": "..."}]
+    row_errors JSONB NOT NULL DEFAULT '[]',
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_imports_created_at ON product_imports (created_at DESC);
+
+-- İçe aktarma ürünleri SKU ile eşleştirir. SKU mevcut veride benzersiz olmayabileceği
+-- için benzersiz indeks yerine arama indeksi kullanılır; çakışmalar satır hatası olarak raporlanır.
+CREATE INDEX IF NOT EXISTS idx_products_sku ON products (sku) WHERE sku <> '';
@@ -0,0 +1,43 @@
+-- Yapılandırılmış ürün özellikleri: tipli özellik tanımları (text, number, enum, boolean),
+-- kategorilere atama (alt kategoriler üst kategorinin özelliklerini de kullanır) ve ürün değerleri
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('text', 'number', 'enum', 'boolean')),
+    unit TEXT NOT NULL DEFAULT '',
+    -- enum tipinde izin verilen değerler
+    options TEXT[] NOT NULL DEFAULT '{}',
+    is_filterable BOOLEAN NOT NULL DEFAULT true,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (type = 'enum' OR cardinality(options) = 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attribute_definitions_code ON attribute_definitions (code);
+
+CREATE TABLE IF NOT EXISTS category_attributes (
+    category_id INTEGER NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attribute_definitions (id) ON DELETE CASCADE,
+    is_required BOOLEAN NOT NULL DEFAULT false,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (category_id, attribute_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_attributes_attribute ON category_attributes (attribute_id);
+
+-- value_text tüm tiplerde kanonik metin değeridir (boolean: 'true'/'false');
+-- number tipinde aralık filtreleri için value_number da doldurulur
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES attribute_definitions (id) ON DELETE RESTRICT,
+    value_text TEXT NOT NULL,
+    value_number NUMERIC,
+    PRIMARY KEY (product_id, attribute_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_text
+    ON product_attribute_values (attribute_id, search_normalize(value_text));
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_number
+    ON product_attribute_values (attribute_id, value_number) WHERE value_number IS NOT NULL;
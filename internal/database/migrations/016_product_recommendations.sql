@@ -0,0 +1,30 @@
+-- İlgili ürün önerileri: periyodik job'ın hesapladığı skorlar (birlikte satın alma, aynı
+-- kategori, fiyat yakınlığı) ve yöneticinin sabitleme / hariç tutma kararları
+CREATE TABLE IF NOT EXISTS product_recommendations (
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    related_product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    -- co_purchase (birlikte satın alınmış) veya category (aynı / kardeş kategori)
+    reason TEXT NOT NULL,
+    co_purchase_count INTEGER NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, related_product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_recommendations_score
+    ON product_recommendations (product_id, score DESC);
+
+CREATE TABLE IF NOT EXISTS product_recommendation_overrides (
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    related_product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('pin', 'exclude')),
+    -- sabitlenen ürünlerin kendi aralarındaki sırası
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, related_product_id),
+    CHECK (product_id <> related_product_id)
+);
+
+-- Birlikte satın alma istatistiği sipariş kalemlerini sipariş bazında eşler
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
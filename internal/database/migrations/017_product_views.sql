@@ -0,0 +1,23 @@
+-- Ürün görüntüleme takibi: görüntüleyen (kullanıcı, oturum veya IP) başına son görüntüleme
+-- ("son baktıklarım" ve tekrar sayımı engelleme için) ve ürün başına günlük görüntüleme sayısı
+CREATE TABLE IF NOT EXISTS product_viewers (
+    -- 'u:<user id>', 's:<X-Session-ID>' veya 'ip:<adres>'
+    viewer_key TEXT NOT NULL,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    viewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- görüntülemenin günlük sayaca en son eklendiği an; debounce süresi içinde tekrar sayılmaz
+    counted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (viewer_key, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_viewers_recent ON product_viewers (viewer_key, viewed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_product_viewers_viewed_at ON product_viewers (viewed_at);
+
+CREATE TABLE IF NOT EXISTS product_view_daily (
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_view_daily_day ON product_view_daily (day);
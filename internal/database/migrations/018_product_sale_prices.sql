@@ -0,0 +1,36 @@
+-- İndirimli / karşılaştırma fiyatları ve fiyat geçmişi. price ürünün normal fiyatıdır;
+-- sale_price yalnızca [sale_starts_at, sale_ends_at) aralığında geçerlidir (boş uç = sınırsız).
+-- compare_at_price indirim yokken de gösterilebilen "önceki fiyat"tır.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS compare_at_price NUMERIC(12, 2) CHECK (compare_at_price >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_price NUMERIC(12, 2) CHECK (sale_price >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_starts_at TIMESTAMPTZ;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sale_ends_at TIMESTAMPTZ;
+ALTER TABLE products ADD CONSTRAINT products_sale_window
+    CHECK (sale_starts_at IS NULL OR sale_ends_at IS NULL OR sale_ends_at > sale_starts_at);
+
+CREATE TABLE IF NOT EXISTS product_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price NUMERIC(12, 2) NOT NULL,
+    compare_at_price NUMERIC(12, 2),
+    sale_price NUMERIC(12, 2),
+    sale_starts_at TIMESTAMPTZ,
+    sale_ends_at TIMESTAMPTZ,
+    -- değişikliğin kaynağı: initial, create, update, import
+    source TEXT NOT NULL,
+    changed_by TEXT,
+    -- fiyat maliyetin altına onayla (allow_below_cost) indirildiyse true
+    below_cost_override BOOLEAN NOT NULL DEFAULT false,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_price_history_product
+    ON product_price_history (product_id, changed_at DESC, id DESC);
+
+-- Mevcut fiyatlar geçmişin başlangıç kaydı olur
+INSERT INTO product_price_history (product_id, price, source, changed_at)
+SELECT id, COALESCE(price, 0), 'initial', COALESCE(updated_at, created_at, NOW())
+FROM products;
+
+-- İçe aktarmada maliyetin altındaki fiyatlar için onay
+ALTER TABLE product_imports ADD COLUMN IF NOT EXISTS allow_below_cost BOOLEAN NOT NULL DEFAULT false;
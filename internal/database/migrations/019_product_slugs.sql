@@ -0,0 +1,41 @@
+-- Ürünler sayısal ID yanında benzersiz slug ile de adreslenir. Slug değiştiğinde eski
+-- slug yönlendirme tablosunda tutulur; eski linkler yeni slug'a yönlendirilir.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products (slug);
+
+CREATE TABLE IF NOT EXISTS product_slug_redirects (
+    slug TEXT PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_slug_redirects_product ON product_slug_redirects (product_id);
+
+-- Mevcut ürünlere başlıktan slug üretilir (kategori migration'ındaki dönüşümle aynı);
+-- çakışmalarda sonuna -2, -3... eklenir, başlıktan slug çıkmazsa "urun-<id>" kullanılır.
+DO $$
+DECLARE
+    r RECORD;
+    base TEXT;
+    candidate TEXT;
+    n INTEGER;
+BEGIN
+    FOR r IN SELECT id, title FROM products WHERE slug IS NULL ORDER BY id LOOP
+        base := TRIM(BOTH '-' FROM REGEXP_REPLACE(
+            LOWER(TRANSLATE(TRIM(COALESCE(r.title, '')), 'İIÇĞÖŞÜÂÎÛçğıöşüâîû', 'iicgosuaiucgiosuaiu')),
+            '[^a-z0-9]+', '-', 'g'
+        ));
+        IF base = '' THEN
+            base := 'urun-' || r.id;
+        END IF;
+        candidate := base;
+        n := 1;
+        WHILE EXISTS (SELECT 1 FROM products WHERE slug = candidate) LOOP
+            n := n + 1;
+            candidate := base || '-' || n;
+        END LOOP;
+        UPDATE products SET slug = candidate WHERE id = r.id;
+    END LOOP;
+END $$;
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
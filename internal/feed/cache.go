@@ -0,0 +1,106 @@
+// ========================================
+// internal/feed/cache.go - ÜRETİLMİŞ FEED ÖNBELLEĞİ
+// ========================================
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrUnknownFormat = errors.New("Bilinmeyen feed formatı")
+
+// Snapshot bir formatın son üretilmiş hali
+type Snapshot struct {
+	Body        []byte
+	ContentType string
+	ETag        string
+	GeneratedAt time.Time
+	Count       int
+}
+
+// Cache feed'leri bellekte tutar. Refresh tüm formatları tek veritabanı okumasıyla yeniden
+// üretir ve atomik olarak değiştirir; istekler üretim sürerken eski sürümü almaya devam eder.
+type Cache struct {
+	opts Options
+
+	mu        sync.RWMutex
+	snapshots map[string]*Snapshot
+
+	refreshMu sync.Mutex // aynı anda tek üretim
+}
+
+func NewCache(opts Options) *Cache {
+	return &Cache{opts: opts, snapshots: map[string]*Snapshot{}}
+}
+
+// Refresh ürünleri okuyup tüm formatları yeniden üretir
+func (c *Cache) Refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	return c.refresh(ctx)
+}
+
+func (c *Cache) refresh(ctx context.Context) error {
+	items, err := LoadItems(ctx, c.opts)
+	if err != nil {
+		return err
+	}
+	categories, err := LoadCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	generatedAt := time.Now().UTC()
+	snapshots := make(map[string]*Snapshot, len(Formats))
+	for format, contentType := range Formats {
+		body, err := Render(format, items, categories, c.opts, generatedAt)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(body)
+		snapshots[format] = &Snapshot{
+			Body:        body,
+			ContentType: contentType,
+			ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+			GeneratedAt: generatedAt,
+			Count:       len(items),
+		}
+	}
+
+	c.mu.Lock()
+	c.snapshots = snapshots
+	c.mu.Unlock()
+	return nil
+}
+
+// Get formatın önbellekteki halini döner; henüz üretilmemişse (ilk istek, job'dan önce) üretir
+func (c *Cache) Get(ctx context.Context, format string) (*Snapshot, error) {
+	if _, ok := Formats[format]; !ok {
+		return nil, ErrUnknownFormat
+	}
+	if snapshot := c.snapshot(format); snapshot != nil {
+		return snapshot, nil
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	// Kilit beklenirken başka bir istek üretmiş olabilir
+	if snapshot := c.snapshot(format); snapshot != nil {
+		return snapshot, nil
+	}
+	if err := c.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return c.snapshot(format), nil
+}
+
+func (c *Cache) snapshot(format string) *Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snapshots[format]
+}
@@ -0,0 +1,364 @@
+// ========================================
+// internal/feed/feed.go - PAZARYERİ VE REKLAM PLATFORMU ÜRÜN FEED'LERİ
+// ========================================
+package feed
+
+import (
+	"context"
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Item feed'e giren tek ürün; tüm formatlar bu yapıdan üretilir
+type Item struct {
+	ID                   int      `json:"id"`
+	SKU                  string   `json:"sku"`
+	Title                string   `json:"title"`
+	Slug                 string   `json:"slug"`
+	Description          string   `json:"description"`
+	Link                 string   `json:"link"`
+	ImageLink            string   `json:"image_link"`
+	AdditionalImageLinks []string `json:"additional_image_links"`
+	Price                float64  `json:"price"`
+	// Bitmemiş (aktif veya planlanmış) indirim; bitiş tarihi geçen indirimler feed'e girmez
+	SalePrice      *float64   `json:"sale_price,omitempty"`
+	SaleStartsAt   *time.Time `json:"sale_starts_at,omitempty"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty"`
+	Currency       string     `json:"currency"`
+	StockStatus    string     `json:"stock_status"` // IN_STOCK, LOW_STOCK, OUT_OF_STOCK
+	AvailableStock int        `json:"available_stock"`
+	Category       string     `json:"category"`
+	CategoryPath   string     `json:"category_path"` // "Giyim > Kadın > Elbise"
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Availability Google Merchant availability değeri
+func (item Item) Availability() string {
+	if item.StockStatus == "OUT_OF_STOCK" {
+		return "out_of_stock"
+	}
+	return "in_stock"
+}
+
+// Options feed'lerin üretim ayarları
+type Options struct {
+	Title        string
+	BaseURL      string
+	ImageBaseURL string
+	ProductPath  string
+	CategoryPath string
+	Currency     string
+	Fields       []FieldMapping
+}
+
+// OptionsFromConfig ayarları config'ten okur; FEED_FIELD_MAPPINGS varsayılan alan
+// eşlemelerinin üzerine yazılır
+func OptionsFromConfig(cfg *config.Config) Options {
+	return Options{
+		Title:        cfg.FeedTitle,
+		BaseURL:      strings.TrimRight(cfg.FeedBaseURL, "/"),
+		ImageBaseURL: strings.TrimRight(cfg.FeedImageBaseURL, "/"),
+		ProductPath:  cfg.FeedProductPath,
+		CategoryPath: cfg.FeedCategoryPath,
+		Currency:     strings.ToUpper(cfg.FeedCurrency),
+		Fields:       ParseFieldMappings(cfg.FeedFieldMappings),
+	}
+}
+
+// Alan kaynakları: Merchant feed alanlarının Item'ın hangi değerinden doldurulacağı.
+// "=" ile başlayan kaynaklar sabit değerdir (ör: "brand:=Acme").
+const (
+	SourceID               = "id"
+	SourceSKU              = "sku"
+	SourceTitle            = "title"
+	SourceDescription      = "description"
+	SourceLink             = "link"
+	SourceImage            = "image"
+	SourceAdditionalImages = "additional_images"
+	SourceAvailability     = "availability"
+	SourcePrice            = "price"
+	SourceSalePrice        = "sale_price"
+	SourceSaleEffective    = "sale_price_effective_date"
+	SourceCategory         = "category"
+	SourceCategoryPath     = "category_path"
+)
+
+// Merchant Center sınırları
+const (
+	maxAdditionalImages   = 10
+	maxMerchantTitleRunes = 150
+	maxMerchantDescRunes  = 5000
+)
+
+var knownSources = map[string]bool{
+	SourceID: true, SourceSKU: true, SourceTitle: true, SourceDescription: true, SourceLink: true,
+	SourceImage: true, SourceAdditionalImages: true, SourceAvailability: true, SourcePrice: true,
+	SourceSalePrice: true, SourceSaleEffective: true, SourceCategory: true, SourceCategoryPath: true,
+}
+
+// FieldMapping Merchant feed alanı (Attribute) ile kaynağı
+type FieldMapping struct {
+	Attribute string
+	Source    string
+}
+
+// defaultFieldMappings Google Merchant Center'ın zorunlu alanlarını karşılar. Ürünlerde marka
+// ve GTIN bulunmadığı için identifier_exists=no gönderilir; marka FEED_FIELD_MAPPINGS ile eklenebilir.
+func defaultFieldMappings() []FieldMapping {
+	return []FieldMapping{
+		{Attribute: "id", Source: SourceID},
+		{Attribute: "title", Source: SourceTitle},
+		{Attribute: "description", Source: SourceDescription},
+		{Attribute: "link", Source: SourceLink},
+		{Attribute: "image_link", Source: SourceImage},
+		{Attribute: "additional_image_link", Source: SourceAdditionalImages},
+		{Attribute: "availability", Source: SourceAvailability},
+		{Attribute: "price", Source: SourcePrice},
+		{Attribute: "sale_price", Source: SourceSalePrice},
+		{Attribute: "sale_price_effective_date", Source: SourceSaleEffective},
+		{Attribute: "product_type", Source: SourceCategoryPath},
+		{Attribute: "condition", Source: "=new"},
+		{Attribute: "mpn", Source: SourceSKU},
+		{Attribute: "identifier_exists", Source: "=no"},
+	}
+}
+
+// ParseFieldMappings "alan:kaynak" çiftlerini (virgülle ayrılmış) varsayılan eşlemelere uygular.
+// Mevcut alanın kaynağı değişir, yeni alan sona eklenir, kaynağı boş bırakılan alan çıkarılır.
+// Bilinmeyen kaynaklar log'lanıp atlanır.
+func ParseFieldMappings(raw string) []FieldMapping {
+	fields := defaultFieldMappings()
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		attribute, source, ok := strings.Cut(pair, ":")
+		attribute, source = strings.TrimSpace(attribute), strings.TrimSpace(source)
+		if !ok || attribute == "" {
+			log.Printf("Invalid feed field mapping %q, expected attribute:source", pair)
+			continue
+		}
+		if source != "" && !strings.HasPrefix(source, "=") && !knownSources[source] {
+			log.Printf("Unknown feed field source %q for %s, skipping", source, attribute)
+			continue
+		}
+
+		index := -1
+		for i, field := range fields {
+			if field.Attribute == attribute {
+				index = i
+				break
+			}
+		}
+		switch {
+		case source == "" && index >= 0:
+			fields = append(fields[:index], fields[index+1:]...)
+		case source == "":
+		case index >= 0:
+			fields[index].Source = source
+		default:
+			fields = append(fields, FieldMapping{Attribute: attribute, Source: source})
+		}
+	}
+	return fields
+}
+
+// Values alanın ürün için değerlerini döner; additional_images birden çok değer üretebilir
+func (m FieldMapping) Values(item Item) []string {
+	if strings.HasPrefix(m.Source, "=") {
+		return []string{strings.TrimPrefix(m.Source, "=")}
+	}
+
+	var value string
+	switch m.Source {
+	case SourceID:
+		value = strconv.Itoa(item.ID)
+	case SourceSKU:
+		value = item.SKU
+	case SourceTitle:
+		value = truncateRunes(item.Title, maxMerchantTitleRunes)
+	case SourceDescription:
+		value = truncateRunes(item.Description, maxMerchantDescRunes)
+	case SourceLink:
+		value = item.Link
+	case SourceImage:
+		value = item.ImageLink
+	case SourceAdditionalImages:
+		return item.AdditionalImageLinks
+	case SourceAvailability:
+		value = item.Availability()
+	case SourcePrice:
+		value = FormatPrice(item.Price, item.Currency)
+	case SourceSalePrice:
+		if item.SalePrice != nil {
+			value = FormatPrice(*item.SalePrice, item.Currency)
+		}
+	case SourceSaleEffective:
+		value = item.SaleEffectiveDate()
+	case SourceCategory:
+		value = item.Category
+	case SourceCategoryPath:
+		value = item.CategoryPath
+	}
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+// SaleEffectiveDate Merchant sale_price_effective_date değeri: "başlangıç/bitiş" (ISO 8601).
+// Tarih verilmeyen uç, indirimin açık ucu olarak feed üretim anı / 1 yıl sonrası ile doldurulur.
+func (item Item) SaleEffectiveDate() string {
+	if item.SalePrice == nil || (item.SaleStartsAt == nil && item.SaleEndsAt == nil) {
+		return ""
+	}
+	start, end := time.Now(), time.Now().AddDate(1, 0, 0)
+	if item.SaleStartsAt != nil {
+		start = *item.SaleStartsAt
+	}
+	if item.SaleEndsAt != nil {
+		end = *item.SaleEndsAt
+	}
+	return start.UTC().Format(time.RFC3339) + "/" + end.UTC().Format(time.RFC3339)
+}
+
+// FormatPrice Merchant fiyat biçimi: "129.90 TRY"
+func FormatPrice(price float64, currency string) string {
+	return strconv.FormatFloat(price, 'f', 2, 64) + " " + currency
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+// LoadItems aktif ve arşivlenmemiş ürünleri kategori yolu, galeri görselleri ve stok
+// durumuyla birlikte okur
+func LoadItems(ctx context.Context, opts Options) ([]Item, error) {
+	rows, err := database.DB.QueryContext(ctx, `
+		WITH RECURSIVE category_paths AS (
+			SELECT id, name::text AS path FROM categories WHERE parent_id IS NULL
+			UNION ALL
+			SELECT c.id, cp.path || ' > ' || c.name
+			FROM categories c JOIN category_paths cp ON c.parent_id = cp.id
+		)
+		SELECT p.id, COALESCE(p.sku, ''), COALESCE(p.title, ''), p.slug, COALESCE(p.description, ''),
+		       COALESCE(p.price, 0), COALESCE(p.image, ''), COALESCE(p.category, ''),
+		       CASE WHEN p.sale_ends_at IS NULL OR p.sale_ends_at > NOW() THEN p.sale_price END,
+		       CASE WHEN p.sale_ends_at IS NULL OR p.sale_ends_at > NOW() THEN p.sale_starts_at END,
+		       p.sale_ends_at,
+		       COALESCE(cp.path, p.category, ''),
+		       CASE
+		           WHEN vs.has_variants THEN
+		               CASE
+		                   WHEN vs.available <= 0 THEN 'OUT_OF_STOCK'
+		                   WHEN vs.above_min THEN 'IN_STOCK'
+		                   ELSE 'LOW_STOCK'
+		               END
+		           WHEN i.id IS NULL OR st.available <= 0 THEN 'OUT_OF_STOCK'
+		           WHEN st.available <= i.min_stock_level THEN 'LOW_STOCK'
+		           ELSE 'IN_STOCK'
+		       END,
+		       CASE WHEN vs.has_variants THEN vs.available ELSE GREATEST(st.available, 0) END,
+		       p.updated_at,
+		       COALESCE((SELECT array_agg(pi.url ORDER BY pi.position, pi.id)
+		                 FROM product_images pi WHERE pi.product_id = p.id), '{}')
+		FROM products p
+		LEFT JOIN inventory i ON i.product_id = p.id
+		-- Sepetlerdeki süresi dolmamış hold'lar mevcut stoktan düşülür; aktif varyantı olan
+		-- ürünlerde stok varyant envanterlerinin toplamıdır (ürün sorgularıyla aynı)
+		CROSS JOIN LATERAL (
+		    SELECT COALESCE(i.quantity - i.reserved_quantity, 0) - COALESCE((
+		        SELECT SUM(h.quantity) FROM cart_holds h
+		        WHERE h.product_id = p.id AND h.variant_id IS NULL AND h.expires_at > NOW()
+		    ), 0) AS available
+		) st
+		CROSS JOIN LATERAL (
+		    SELECT COUNT(*) > 0 AS has_variants,
+		           COALESCE(SUM(GREATEST(va.available, 0)), 0) AS available,
+		           COALESCE(BOOL_OR(va.available > va.min_level), false) AS above_min
+		    FROM (
+		        SELECT COALESCE(vi.quantity - vi.reserved_quantity, 0) - COALESCE((
+		                   SELECT SUM(h.quantity) FROM cart_holds h
+		                   WHERE h.variant_id = pv.id AND h.expires_at > NOW()
+		               ), 0) AS available,
+		               COALESCE(vi.min_stock_level, 0) AS min_level
+		        FROM product_variants pv
+		        LEFT JOIN variant_inventory vi ON vi.variant_id = pv.id
+		        WHERE pv.product_id = p.id AND pv.is_active = true
+		    ) va
+		) vs
+		LEFT JOIN category_paths cp ON cp.id = p.category_id
+		WHERE p.is_active = true AND p.deleted_at IS NULL
+		ORDER BY p.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		var image string
+		var gallery []string
+		if err := rows.Scan(
+			&item.ID, &item.SKU, &item.Title, &item.Slug, &item.Description, &item.Price, &image, &item.Category,
+			&item.SalePrice, &item.SaleStartsAt, &item.SaleEndsAt,
+			&item.CategoryPath, &item.StockStatus, &item.AvailableStock, &item.UpdatedAt, pq.Array(&gallery),
+		); err != nil {
+			return nil, err
+		}
+		item.Currency = opts.Currency
+		if item.SalePrice == nil {
+			item.SaleStartsAt, item.SaleEndsAt = nil, nil
+		}
+		item.Link = opts.productURL(item)
+
+		// Birincil görsel products.image'dır; galerideki diğer görseller ek görsel olur
+		item.ImageLink = opts.absoluteURL(image)
+		for _, link := range gallery {
+			link = opts.absoluteURL(link)
+			if item.ImageLink == "" {
+				item.ImageLink = link
+				continue
+			}
+			if link != item.ImageLink && len(item.AdditionalImageLinks) < maxAdditionalImages {
+				item.AdditionalImageLinks = append(item.AdditionalImageLinks, link)
+			}
+		}
+		if item.AdditionalImageLinks == nil {
+			item.AdditionalImageLinks = []string{}
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (opts Options) productURL(item Item) string {
+	path := strings.NewReplacer(
+		"{id}", strconv.Itoa(item.ID),
+		"{sku}", url.PathEscape(item.SKU),
+		"{slug}", url.PathEscape(item.Slug),
+	).Replace(opts.ProductPath)
+	return opts.BaseURL + "/" + strings.TrimLeft(path, "/")
+}
+
+// absoluteURL göreli yolları (yerel storage: /uploads/...) mutlak URL'e çevirir
+func (opts Options) absoluteURL(raw string) string {
+	if raw == "" || strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		return raw
+	}
+	return opts.ImageBaseURL + "/" + strings.TrimLeft(raw, "/")
+}
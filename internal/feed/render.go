@@ -0,0 +1,119 @@
+// ========================================
+// internal/feed/render.go - FEED FORMATLARI (GOOGLE RSS/XML, TSV, JSON)
+// ========================================
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// Feed formatları (dosya adları endpoint yollarında da kullanılır)
+const (
+	FormatGoogleXML = "google.xml"
+	FormatGoogleTSV = "google.tsv"
+	FormatJSON      = "catalog.json"
+	FormatSitemap   = "sitemap.xml"
+)
+
+// Formats desteklenen tüm formatlar ve Content-Type değerleri
+var Formats = map[string]string{
+	FormatGoogleXML: "application/xml; charset=utf-8",
+	FormatGoogleTSV: "text/tab-separated-values; charset=utf-8",
+	FormatJSON:      "application/json; charset=utf-8",
+	FormatSitemap:   "application/xml; charset=utf-8",
+}
+
+// Render ürünleri (sitemap için kategorilerle birlikte) istenen formatta üretir
+func Render(format string, items []Item, categories []Category, opts Options, generatedAt time.Time) ([]byte, error) {
+	switch format {
+	case FormatSitemap:
+		return renderSitemap(items, categories, opts), nil
+	case FormatGoogleXML:
+		return renderGoogleXML(items, opts)
+	case FormatGoogleTSV:
+		return renderGoogleTSV(items, opts), nil
+	default:
+		return renderJSON(items, opts, generatedAt)
+	}
+}
+
+// renderGoogleXML Google Merchant Center RSS 2.0 feed'i; tüm alanlar g: namespace'indedir
+func renderGoogleXML(items []Item, opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<rss version="2.0" xmlns:g="http://base.google.com/ns/1.0">` + "\n<channel>\n")
+	writeXMLElement(&buf, "title", opts.Title)
+	writeXMLElement(&buf, "link", opts.BaseURL)
+	writeXMLElement(&buf, "description", opts.Title)
+
+	for _, item := range items {
+		buf.WriteString("<item>\n")
+		for _, field := range opts.Fields {
+			for _, value := range field.Values(item) {
+				writeXMLElement(&buf, "g:"+field.Attribute, value)
+			}
+		}
+		buf.WriteString("</item>\n")
+	}
+	buf.WriteString("</channel>\n</rss>\n")
+	return buf.Bytes(), nil
+}
+
+func writeXMLElement(buf *bytes.Buffer, name, value string) {
+	buf.WriteString("<" + name + ">")
+	xml.EscapeText(buf, []byte(value))
+	buf.WriteString("</" + name + ">\n")
+}
+
+// renderGoogleTSV Merchant Center'ın tab ayraçlı metin formatı; ilk satır alan adlarıdır.
+// Birden çok değerli alanlar (additional_image_link) virgülle birleştirilir.
+func renderGoogleTSV(items []Item, opts Options) []byte {
+	var buf bytes.Buffer
+	for i, field := range opts.Fields {
+		if i > 0 {
+			buf.WriteByte('\t')
+		}
+		buf.WriteString(field.Attribute)
+	}
+	buf.WriteByte('\n')
+
+	for _, item := range items {
+		for i, field := range opts.Fields {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			buf.WriteString(tsvValue(strings.Join(field.Values(item), ",")))
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// tsvValue sekme ve satır sonlarını boşluğa çevirir; TSV'de kaçış karakteri yoktur
+func tsvValue(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == '\t' || r == '\n' || r == '\r'
+	}), " ")
+}
+
+// renderJSON genel amaçlı katalog feed'i; alan eşlemeleri yalnızca Merchant
+// formatlarına uygulanır, JSON tüm ürün alanlarını içerir
+func renderJSON(items []Item, opts Options, generatedAt time.Time) ([]byte, error) {
+	return json.Marshal(struct {
+		Title       string    `json:"title"`
+		Link        string    `json:"link"`
+		GeneratedAt time.Time `json:"generated_at"`
+		Count       int       `json:"count"`
+		Products    []Item    `json:"products"`
+	}{
+		Title:       opts.Title,
+		Link:        opts.BaseURL,
+		GeneratedAt: generatedAt,
+		Count:       len(items),
+		Products:    items,
+	})
+}
@@ -0,0 +1,93 @@
+// ========================================
+// internal/feed/sitemap.go - ARAMA MOTORLARI İÇİN SITEMAP.XML
+// ========================================
+package feed
+
+import (
+	"bytes"
+	"context"
+	"ecommerce-backend/internal/database"
+	"encoding/xml"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxSitemapURLs tek sitemap dosyasındaki URL sınırı (sitemaps.org)
+const maxSitemapURLs = 50000
+
+// Category sitemap'e giren aktif kategori
+type Category struct {
+	Slug      string
+	UpdatedAt time.Time
+}
+
+// LoadCategories aktif kategorileri döner; pasif bir üst kategorinin altındakiler
+// mağazada görünmediği için dahil edilmez
+func LoadCategories(ctx context.Context) ([]Category, error) {
+	rows, err := database.DB.QueryContext(ctx, `
+		WITH RECURSIVE visible AS (
+			SELECT id, slug, updated_at FROM categories WHERE parent_id IS NULL AND is_active = true
+			UNION ALL
+			SELECT c.id, c.slug, c.updated_at
+			FROM categories c JOIN visible v ON c.parent_id = v.id
+			WHERE c.is_active = true
+		)
+		SELECT slug, updated_at FROM visible ORDER BY slug
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+	for rows.Next() {
+		var category Category
+		if err := rows.Scan(&category.Slug, &category.UpdatedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// renderSitemap ana sayfa, kategori ve ürün sayfalarını lastmod ile listeler
+func renderSitemap(items []Item, categories []Category, opts Options) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+
+	count := 0
+	writeURL := func(loc string, lastmod time.Time) bool {
+		if count >= maxSitemapURLs {
+			return false
+		}
+		count++
+		buf.WriteString("<url>\n")
+		writeXMLElement(&buf, "loc", loc)
+		if !lastmod.IsZero() {
+			writeXMLElement(&buf, "lastmod", lastmod.UTC().Format(time.RFC3339))
+		}
+		buf.WriteString("</url>\n")
+		return true
+	}
+
+	writeURL(opts.BaseURL+"/", time.Time{})
+	for _, category := range categories {
+		writeURL(opts.categoryURL(category), category.UpdatedAt)
+	}
+	for _, item := range items {
+		if !writeURL(item.Link, item.UpdatedAt) {
+			log.Printf("Sitemap truncated at %d URLs", maxSitemapURLs)
+			break
+		}
+	}
+	buf.WriteString("</urlset>\n")
+	return buf.Bytes()
+}
+
+func (opts Options) categoryURL(category Category) string {
+	path := strings.ReplaceAll(opts.CategoryPath, "{slug}", url.PathEscape(category.Slug))
+	return opts.BaseURL + "/" + strings.TrimLeft(path, "/")
+}
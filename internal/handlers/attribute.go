@@ -0,0 +1,342 @@
+// ========================================
+// internal/handlers/attribute.go - ÖZELLİK TANIMLARI VE KATEGORİ ATAMALARI
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type AttributeHandler struct {
+	cfg *config.Config
+}
+
+func NewAttributeHandler(cfg *config.Config) *AttributeHandler {
+	return &AttributeHandler{cfg: cfg}
+}
+
+const attributeColumnsSQL = `id, code, name, type, unit, options, is_filterable, sort_order, created_at, updated_at`
+
+func scanAttributeDefinition(row interface{ Scan(...interface{}) error }) (models.AttributeDefinition, error) {
+	var attribute models.AttributeDefinition
+	err := row.Scan(
+		&attribute.ID, &attribute.Code, &attribute.Name, &attribute.Type, &attribute.Unit,
+		pq.Array(&attribute.Options), &attribute.IsFilterable, &attribute.SortOrder,
+		&attribute.CreatedAt, &attribute.UpdatedAt,
+	)
+	return attribute, err
+}
+
+// GetAttributes tüm özellik tanımlarını listeler
+func (h *AttributeHandler) GetAttributes(c *gin.Context) {
+	rows, err := database.DB.Query("SELECT " + attributeColumnsSQL + " FROM attribute_definitions ORDER BY sort_order, name")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellikler alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	attributes := []models.AttributeDefinition{}
+	for rows.Next() {
+		attribute, err := scanAttributeDefinition(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellikler okunamadı: " + err.Error()})
+			return
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attributes": attributes})
+}
+
+// CreateAttribute yeni özellik tanımı ekler; kod verilmezse addan üretilir
+func (h *AttributeHandler) CreateAttribute(c *gin.Context) {
+	var req models.CreateAttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	code := slugify(req.Code)
+	if code == "" {
+		code = slugify(name)
+	}
+	if name == "" || code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Özellik adı gerekli"})
+		return
+	}
+	options, err := attributeOptions(req.Type, req.Options)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	isFilterable := true
+	if req.IsFilterable != nil {
+		isFilterable = *req.IsFilterable
+	}
+
+	attribute, err := scanAttributeDefinition(database.DB.QueryRow(`
+		INSERT INTO attribute_definitions (code, name, type, unit, options, is_filterable, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING `+attributeColumnsSQL,
+		code, name, req.Type, strings.TrimSpace(req.Unit), pq.Array(options), isFilterable, req.SortOrder,
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu kodda bir özellik zaten var", "code": code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellik oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"attribute": attribute})
+}
+
+// UpdateAttribute özelliğin adını, birimini, seçeneklerini ve filtre ayarını günceller
+func (h *AttributeHandler) UpdateAttribute(c *gin.Context) {
+	attributeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz attribute ID"})
+		return
+	}
+
+	var req models.UpdateAttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	existing, err := scanAttributeDefinition(tx.QueryRow(
+		"SELECT "+attributeColumnsSQL+" FROM attribute_definitions WHERE id = $1 FOR UPDATE", attributeID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Özellik bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellik alınamadı: " + err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		if existing.Name = strings.TrimSpace(*req.Name); existing.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Özellik adı boş olamaz"})
+			return
+		}
+	}
+	if req.Unit != nil {
+		existing.Unit = strings.TrimSpace(*req.Unit)
+	}
+	if req.IsFilterable != nil {
+		existing.IsFilterable = *req.IsFilterable
+	}
+	if req.SortOrder != nil {
+		existing.SortOrder = *req.SortOrder
+	}
+	if req.Options != nil {
+		options, err := attributeOptions(existing.Type, req.Options)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Ürünlerde kullanılan değerler listeden çıkarılamaz
+		inUse, err := attributeValuesNotIn(tx, attributeID, options)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellik değerleri alınamadı: " + err.Error()})
+			return
+		}
+		if len(inUse) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ürünlerde kullanılan seçenekler kaldırılamaz", "in_use": inUse})
+			return
+		}
+		existing.Options = options
+	}
+
+	updated, err := scanAttributeDefinition(tx.QueryRow(`
+		UPDATE attribute_definitions
+		SET name = $2, unit = $3, options = $4, is_filterable = $5, sort_order = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+attributeColumnsSQL,
+		attributeID, existing.Name, existing.Unit, pq.Array(existing.Options), existing.IsFilterable, existing.SortOrder,
+	))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellik güncellenemedi: " + err.Error()})
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attribute": updated})
+}
+
+// DeleteAttribute ürünlerde kullanılmayan özelliği siler; kategori atamaları da kalkar
+func (h *AttributeHandler) DeleteAttribute(c *gin.Context) {
+	attributeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz attribute ID"})
+		return
+	}
+
+	var productCount int
+	if err := database.DB.QueryRow(
+		"SELECT COUNT(*) FROM product_attribute_values WHERE attribute_id = $1", attributeID,
+	).Scan(&productCount); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellik kullanımı kontrol edilemedi: " + err.Error()})
+		return
+	}
+	if productCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ürünlerde kullanılan özellik silinemez", "products": productCount})
+		return
+	}
+
+	result, err := database.DB.Exec("DELETE FROM attribute_definitions WHERE id = $1", attributeID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ürünlerde kullanılan özellik silinemez"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Özellik silinemedi: " + err.Error()})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Özellik bulunamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Özellik silindi", "attribute_id": attributeID})
+}
+
+// attributeOptions enum seçeneklerini temizler ve tekrarları (Türkçe/büyük-küçük harf
+// duyarsız) ayıklar; enum dışındaki tiplerde seçenek verilemez
+func attributeOptions(attributeType string, raw []string) ([]string, error) {
+	options := []string{}
+	seen := map[string]bool{}
+	for _, option := range raw {
+		option = strings.TrimSpace(option)
+		if key := slugify(option); option != "" && !seen[key] {
+			seen[key] = true
+			options = append(options, option)
+		}
+	}
+	if attributeType == attributeTypeEnum && len(options) == 0 {
+		return nil, &attributeError{Message: "enum tipindeki özellik için en az bir seçenek gerekli"}
+	}
+	if attributeType != attributeTypeEnum && len(options) > 0 {
+		return nil, &attributeError{Message: "Seçenekler yalnızca enum tipinde kullanılabilir"}
+	}
+	return options, nil
+}
+
+// attributeValuesNotIn özelliğin ürünlerde kullanılan ama options listesinde olmayan değerleri
+func attributeValuesNotIn(q queryer, attributeID int, options []string) ([]string, error) {
+	rows, err := q.Query("SELECT DISTINCT value_text FROM product_attribute_values WHERE attribute_id = $1", attributeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	allowed := make(map[string]bool, len(options))
+	for _, option := range options {
+		allowed[option] = true
+	}
+	missing := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		if !allowed[value] {
+			missing = append(missing, value)
+		}
+	}
+	return missing, rows.Err()
+}
+
+// SetCategoryAttributes kategoriye doğrudan atanan özellikleri istekteki listeyle değiştirir.
+// Alt kategoriler bu özellikleri otomatik olarak kullanır.
+func (h *CategoryHandler) SetCategoryAttributes(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz category ID"})
+		return
+	}
+
+	var req models.SetCategoryAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err = tx.QueryRow("SELECT id FROM categories WHERE id = $1 FOR UPDATE", categoryID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": errCategoryNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori alınamadı: " + err.Error()})
+		return
+	}
+
+	if _, err = tx.Exec("DELETE FROM category_attributes WHERE category_id = $1", categoryID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori özellikleri güncellenemedi: " + err.Error()})
+		return
+	}
+	for _, assignment := range req.Attributes {
+		_, err = tx.Exec(`
+			INSERT INTO category_attributes (category_id, attribute_id, is_required, sort_order)
+			VALUES ($1, $2, $3, $4)
+		`, categoryID, assignment.AttributeID, assignment.IsRequired, assignment.Position)
+		if err != nil {
+			switch {
+			case isForeignKeyViolation(err):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Özellik bulunamadı", "attribute_id": assignment.AttributeID})
+			case isUniqueViolation(err):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Özellik listede tekrar edemez", "attribute_id": assignment.AttributeID})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori özellikleri güncellenemedi: " + err.Error()})
+			}
+			return
+		}
+	}
+
+	attributes, err := loadCategoryAttributes(tx, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori özellikleri alınamadı: " + err.Error()})
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category_id": categoryID, "attributes": attributes})
+}
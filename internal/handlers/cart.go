@@ -4,11 +4,13 @@
 package handlers
 
 import (
+	"database/sql"
 	"ecommerce-backend/internal/config"
 	"ecommerce-backend/internal/database"
 	"ecommerce-backend/internal/models"
 	"math"
 	"net/http"
+	"sort"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -18,6 +20,7 @@ import (
 type CartHandler struct {
 	cfg            *config.Config
 	supabaseClient *supabase.Client // Opsiyonel: database operasyonları için
+	holds          cartHolds
 }
 
 func NewCartHandler(cfg *config.Config) *CartHandler {
@@ -27,22 +30,48 @@ func NewCartHandler(cfg *config.Config) *CartHandler {
 	return &CartHandler{
 		cfg:            cfg,
 		supabaseClient: client,
+		holds:          newCartHolds(cfg),
 	}
 }
 
 func (h *CartHandler) GetCartItems(c *gin.Context) {
 	userID := c.GetString("userID")
 
-	// DÜZELTME: created_at, updated_at eksikti, time.Time scan için düzeltme
+	// Sepeti görüntülemek de aktivite sayılır, stok hold'larını uzat
+	if err := h.holds.extend(database.DB, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok tutma süresi uzatılamadı: " + err.Error()})
+		return
+	}
+
+	// DÜZELTME: Pasif ürünler artık filtrelenmiyor, uyarı olarak işaretleniyor
+	// Mevcut stoktan diğer kullanıcıların aktif hold'ları düşülür.
+	// Varyantlı satırlarda fiyat ve stok varyanttan gelir.
 	query := `
         SELECT 
-            ci.id, ci.cart_id, ci.product_id, ci.quantity, ci.created_at,
+            ci.id, ci.cart_id, ci.product_id, ci.variant_id, ci.quantity, ci.created_at,
+            COALESCE(ci.price_at_add, v.price, ` + effectivePriceSQL + `),
             p.id, p.title, p.description, p.price, p.image, p.category, 
-            p.sku, p.rating, p.rating_count, p.is_active, p.created_at, p.updated_at
+            p.sku, p.rating, p.rating_count, p.is_active, p.created_at, p.updated_at, p.deleted_at,
+            p.slug, ` + productPriceColumnsSQL + `,
+            v.sku, v.price, COALESCE(v.image, ''), COALESCE(v.is_active, false),
+            CASE WHEN ci.variant_id IS NULL
+                THEN COALESCE((i.quantity - i.reserved_quantity), 0)
+                ELSE COALESCE((vi.quantity - vi.reserved_quantity), 0)
+            END - COALESCE((
+                SELECT SUM(oh.quantity) FROM cart_holds oh
+                WHERE oh.product_id = ci.product_id AND oh.variant_id IS NOT DISTINCT FROM ci.variant_id
+                  AND oh.expires_at > NOW() AND oh.user_id <> ca.user_id
+            ), 0) as available_stock,
+            h.expires_at
         FROM cart_items ci
         JOIN carts ca ON ci.cart_id = ca.id
         JOIN products p ON ci.product_id = p.id
-        WHERE ca.user_id = $1 AND p.is_active = true
+        LEFT JOIN inventory i ON i.product_id = p.id
+        LEFT JOIN product_variants v ON v.id = ci.variant_id
+        LEFT JOIN variant_inventory vi ON vi.variant_id = ci.variant_id
+        LEFT JOIN cart_holds h ON h.user_id = ca.user_id AND h.product_id = ci.product_id
+            AND h.variant_id IS NOT DISTINCT FROM ci.variant_id AND h.expires_at > NOW()
+        WHERE ca.user_id = $1
         ORDER BY ci.created_at DESC
     `
 
@@ -54,15 +83,25 @@ func (h *CartHandler) GetCartItems(c *gin.Context) {
 	defer rows.Close()
 
 	var cartItems []models.CartItem
+	var summaryLines []pricedLine
+	var summaryIndexes []int
+	hasWarnings := false
 	for rows.Next() {
 		var item models.CartItem
 		var product models.Product
+		var variantSKU sql.NullString
+		var variant models.ProductVariant
+		var availableStock int
 
 		err := rows.Scan(
-			&item.ID, &item.CartID, &item.ProductID, &item.Quantity, &item.CreatedAt,
+			&item.ID, &item.CartID, &item.ProductID, &item.VariantID, &item.Quantity, &item.CreatedAt,
+			&item.PriceAtAdd,
 			&product.ID, &product.Title, &product.Description, &product.Price, &product.Image,
 			&product.Category, &product.SKU, &product.Rating, &product.RatingCount,
-			&product.IsActive, &product.CreatedAt, &product.UpdatedAt,
+			&product.IsActive, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+			&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
+			&variantSKU, &variant.Price, &variant.Image, &variant.IsActive,
+			&availableStock, &item.HoldExpiresAt,
 		)
 		if err != nil {
 			// Hata log'la ama devam et
@@ -70,7 +109,48 @@ func (h *CartHandler) GetCartItems(c *gin.Context) {
 		}
 
 		item.Product = &product
+		item.UnitPrice = product.EffectivePrice
+		if item.VariantID != nil {
+			variant.ID = *item.VariantID
+			variant.ProductID = product.ID
+			variant.SKU = variantSKU.String
+			variant.EffectivePrice = product.EffectivePrice
+			if variant.Price != nil {
+				variant.EffectivePrice = *variant.Price
+			}
+			item.UnitPrice = variant.EffectivePrice
+			item.Variant = &variant
+		}
+		item.AvailableStock = &availableStock
+		applyCartItemWarnings(&item)
+		if len(item.Warnings) > 0 {
+			hasWarnings = true
+		}
 		cartItems = append(cartItems, item)
+
+		// Satın alınamayan satırlar özete dahil edilmez
+		purchasable := product.IsActive && (item.Variant == nil || item.Variant.IsActive)
+		if purchasable && availableStock > 0 {
+			ownPrice := item.Variant != nil && item.Variant.Price != nil
+			summaryLines = append(summaryLines, pricedLine{
+				ProductID:      item.ProductID,
+				VariantID:      item.VariantID,
+				Quantity:       item.Quantity,
+				UnitPrice:      item.UnitPrice,
+				ReferencePrice: savingsReferencePrice(item.PriceAtAdd, product.OriginalPrice, ownPrice),
+			})
+			summaryIndexes = append(summaryIndexes, len(cartItems)-1)
+		}
+	}
+
+	// EKLEME: CreateOrder ile aynı hesaplama yolu
+	summary, err := calculateTotals(summaryLines)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for i, line := range summaryLines {
+		cartItems[summaryIndexes[i]].LineTotal = line.LineTotal
 	}
 
 	// Boş slice yerine null dönmemesi için garanti altına al
@@ -78,7 +158,11 @@ func (h *CartHandler) GetCartItems(c *gin.Context) {
 		cartItems = []models.CartItem{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": cartItems})
+	c.JSON(http.StatusOK, gin.H{
+		"items":        cartItems,
+		"summary":      summary,
+		"has_warnings": hasWarnings,
+	})
 }
 
 func (h *CartHandler) AddOrUpdateCartItem(c *gin.Context) {
@@ -90,23 +174,45 @@ func (h *CartHandler) AddOrUpdateCartItem(c *gin.Context) {
 		return
 	}
 
+	// DÜZELTME: Tüm kontroller tek transaction içinde, satır kilitleriyle yapılır.
+	// Kilit sırası her zaman sepet -> envanter (product_id sırasıyla), böylece
+	// aynı kullanıcı/ürün için eşzamanlı istekler sıraya girer ve deadlock oluşmaz.
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
 	// EKLEME: Ürün var mı kontrolü
 	var productExists bool
-	productCheckQuery := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_active = true)"
-	err := database.DB.QueryRow(productCheckQuery, req.ProductID).Scan(&productExists)
+	productCheckQuery := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_active = true AND deleted_at IS NULL)"
+	err = tx.QueryRow(productCheckQuery, req.ProductID).Scan(&productExists)
 	if err != nil || !productExists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
 		return
 	}
 
-	// Stok kontrolü yap
-	var availableStock int
-	stockQuery := `
-		SELECT COALESCE((i.quantity - i.reserved_quantity), 0) as available_stock
-		FROM inventory i 
-		WHERE i.product_id = $1
-	`
-	err = database.DB.QueryRow(stockQuery, req.ProductID).Scan(&availableStock)
+	// EKLEME: Varyantlı ürünlerde varyant seçimi zorunlu
+	if err = validateVariant(tx, req.ProductID, req.VariantID); err != nil {
+		respondVariantError(c, err)
+		return
+	}
+
+	// Kullanıcının cart'ını bul veya oluştur (satır kilitlenir)
+	cartID, err := findOrCreateCart(tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cart oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	if err = lockStock(tx, req.ProductID, req.VariantID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok kilitlenemedi: " + err.Error()})
+		return
+	}
+
+	// Stok kontrolü yap (diğer kullanıcıların hold'ları düşülerek)
+	availableStock, err := availableStockForUser(tx, req.ProductID, req.VariantID, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok kontrolü yapılamadı: " + err.Error()})
 		return
@@ -114,13 +220,14 @@ func (h *CartHandler) AddOrUpdateCartItem(c *gin.Context) {
 
 	// EKLEME: Mevcut sepetteki miktarı da kontrol et
 	var currentCartQuantity int
-	currentCartQuery := `
-		SELECT COALESCE(ci.quantity, 0)
-		FROM carts c
-		LEFT JOIN cart_items ci ON c.id = ci.cart_id AND ci.product_id = $2
-		WHERE c.user_id = $1
-	`
-	database.DB.QueryRow(currentCartQuery, userID, req.ProductID).Scan(&currentCartQuantity)
+	err = tx.QueryRow(
+		"SELECT quantity FROM cart_items WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::int",
+		cartID, req.ProductID, req.VariantID,
+	).Scan(&currentCartQuantity)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet alınamadı: " + err.Error()})
+		return
+	}
 
 	totalRequestedQuantity := currentCartQuantity + req.Quantity
 	if availableStock < totalRequestedQuantity {
@@ -134,65 +241,50 @@ func (h *CartHandler) AddOrUpdateCartItem(c *gin.Context) {
 		return
 	}
 
-	// Transaction başlat
-	tx, err := database.DB.Begin()
+	// EKLEME: Sipariş / müşteri başı satın alma limiti (ürünün tüm varyantları birlikte sayılır)
+	otherVariants, err := otherVariantsInCart(tx, cartID, req.ProductID, req.VariantID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet alınamadı: " + err.Error()})
 		return
 	}
-	defer tx.Rollback()
-
-	// Kullanıcının cart'ını bul veya oluştur
-	var cartID int
-	cartQuery := "SELECT id FROM carts WHERE user_id = $1"
-	err = tx.QueryRow(cartQuery, userID).Scan(&cartID)
-
+	violation, err := checkPurchaseLimit(tx, userID, req.ProductID, otherVariants+totalRequestedQuantity)
 	if err != nil {
-		// Cart oluştur
-		insertCartQuery := "INSERT INTO carts (user_id, created_at) VALUES ($1, NOW()) RETURNING id"
-		err = tx.QueryRow(insertCartQuery, userID).Scan(&cartID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Cart oluşturulamadı: " + err.Error()})
-			return
-		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Satın alma limiti kontrol edilemedi: " + err.Error()})
+		return
+	}
+	if violation != nil {
+		respondPurchaseLimit(c, violation)
+		return
 	}
 
-	// Mevcut cart item'ı kontrol et
-	var existingID int
-	var existingQuantity int
-	checkItemQuery := "SELECT id, quantity FROM cart_items WHERE cart_id = $1 AND product_id = $2"
-	err = tx.QueryRow(checkItemQuery, cartID, req.ProductID).Scan(&existingID, &existingQuantity)
-
-	var cartItem models.CartItem
-	if err == nil {
-		// Mevcut item'ı güncelle
-		newQuantity := existingQuantity + req.Quantity
-		updateQuery := `
-			UPDATE cart_items 
-			SET quantity = $1 
-			WHERE id = $2 
-			RETURNING id, cart_id, product_id, quantity, created_at
-		`
-		err = tx.QueryRow(updateQuery, newQuantity, existingID).Scan(
-			&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.Quantity, &cartItem.CreatedAt,
-		)
-	} else {
-		// Yeni item ekle
-		insertQuery := `
-			INSERT INTO cart_items (cart_id, product_id, quantity, created_at) 
-			VALUES ($1, $2, $3, NOW()) 
-			RETURNING id, cart_id, product_id, quantity, created_at
-		`
-		err = tx.QueryRow(insertQuery, cartID, req.ProductID, req.Quantity).Scan(
-			&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.Quantity, &cartItem.CreatedAt,
-		)
+	unitPrice, err := unitPriceFor(tx, req.ProductID, req.VariantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün fiyatı alınamadı: " + err.Error()})
+		return
 	}
 
+	// Satırı ekle veya miktarı artır (unique (cart_id, product_id, variant_id) üzerinden upsert)
+	var cartItem models.CartItem
+	upsertQuery := `
+		INSERT INTO cart_items (cart_id, product_id, variant_id, quantity, price_at_add, created_at) 
+		VALUES ($1, $2, $3, $4, $5, NOW()) 
+		ON CONFLICT (cart_id, product_id, (COALESCE(variant_id, 0))) DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity
+		RETURNING id, cart_id, product_id, variant_id, quantity, COALESCE(price_at_add, 0), created_at
+	`
+	err = tx.QueryRow(upsertQuery, cartID, req.ProductID, req.VariantID, req.Quantity, unitPrice).Scan(
+		&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.VariantID, &cartItem.Quantity, &cartItem.PriceAtAdd, &cartItem.CreatedAt,
+	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet güncellenemedi: " + err.Error()})
 		return
 	}
 
+	// Sepetteki yeni miktar kadar stok tut
+	if err = h.holds.sync(tx, userID, req.ProductID, req.VariantID, cartItem.Quantity); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok tutulamadı: " + err.Error()})
+		return
+	}
+
 	// Transaction commit
 	if err = tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
@@ -202,13 +294,15 @@ func (h *CartHandler) AddOrUpdateCartItem(c *gin.Context) {
 	// Product bilgisini ekle
 	var product models.Product
 	productQuery := `
-		SELECT id, title, description, price, image, category, sku, rating, rating_count, is_active, created_at, updated_at 
-		FROM products WHERE id = $1
+		SELECT id, title, description, price, image, category, sku, rating, rating_count, is_active, created_at, updated_at,
+		       p.slug, ` + productPriceColumnsSQL + `
+		FROM products p WHERE id = $1
 	`
 	err = database.DB.QueryRow(productQuery, req.ProductID).Scan(
 		&product.ID, &product.Title, &product.Description, &product.Price,
 		&product.Image, &product.Category, &product.SKU, &product.Rating,
 		&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
+		&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
 	)
 	if err != nil {
 		// Product bilgisi alamasa bile cart item'ı döndür
@@ -216,6 +310,7 @@ func (h *CartHandler) AddOrUpdateCartItem(c *gin.Context) {
 	} else {
 		cartItem.Product = &product
 	}
+	cartItem.UnitPrice = unitPrice
 
 	c.JSON(http.StatusOK, cartItem)
 }
@@ -227,6 +322,11 @@ func (h *CartHandler) DecrementCartItem(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
 		return
 	}
+	variantID, err := variantIDQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz variant ID"})
+		return
+	}
 
 	// Transaction başlat
 	tx, err := database.DB.Begin()
@@ -236,9 +336,9 @@ func (h *CartHandler) DecrementCartItem(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// Kullanıcının cart'ını bul
+	// Kullanıcının cart'ını bul (son aktivite zamanını da güncelle)
 	var cartID int
-	err = tx.QueryRow("SELECT id FROM carts WHERE user_id = $1", userID).Scan(&cartID)
+	err = tx.QueryRow("UPDATE carts SET updated_at = NOW() WHERE user_id = $1 RETURNING id", userID).Scan(&cartID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Sepet bulunamadı"})
 		return
@@ -248,8 +348,8 @@ func (h *CartHandler) DecrementCartItem(c *gin.Context) {
 	var existingID int
 	var existingQuantity int
 	err = tx.QueryRow(
-		"SELECT id, quantity FROM cart_items WHERE cart_id = $1 AND product_id = $2",
-		cartID, productID,
+		"SELECT id, quantity FROM cart_items WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::int FOR UPDATE",
+		cartID, productID, variantID,
 	).Scan(&existingID, &existingQuantity)
 
 	if err != nil {
@@ -279,6 +379,11 @@ func (h *CartHandler) DecrementCartItem(c *gin.Context) {
 		}
 	}
 
+	if err = h.holds.sync(tx, userID, productID, variantID, newQuantity); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok tutma güncellenemedi: " + err.Error()})
+		return
+	}
+
 	// Transaction commit
 	if err = tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
@@ -287,10 +392,170 @@ func (h *CartHandler) DecrementCartItem(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"product_id":   productID,
+		"variant_id":   variantID,
 		"new_quantity": newQuantity,
 	})
 }
 
+// EKLEME: Sepet satırı için mutlak miktar ayarlama (0 ise satır kaldırılır)
+func (h *CartHandler) SetCartItemQuantity(c *gin.Context) {
+	userID := c.GetString("userID")
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	var req models.SetCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Transaction başlat
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	cartID, err := findOrCreateCart(tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cart oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	result, err := setCartLine(tx, h.holds, userID, cartID, productID, req.VariantID, *req.Quantity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet güncellenemedi: " + err.Error()})
+		return
+	}
+
+	switch result.Status {
+	case cartLineNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	case cartLineVariantRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": errVariantRequired.Error()})
+		return
+	case cartLineInsufficientStock:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Yetersiz stok",
+			"available": result.Available,
+			"requested": result.Quantity,
+		})
+		return
+	case cartLinePurchaseLimit:
+		respondPurchaseLimit(c, result.Limit)
+		return
+	}
+
+	// Transaction commit
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// EKLEME: Birden fazla sepet satırını tek transaction'da güncelle/kaldır.
+// Herhangi bir satır doğrulamadan geçemezse hiçbir değişiklik uygulanmaz.
+func (h *CartHandler) BulkUpdateCartItems(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req models.BulkUpdateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Aynı ürün/varyant için birden fazla işlem gönderilmesini engelle
+	type lineKey struct{ productID, variantID int }
+	seen := make(map[lineKey]bool, len(req.Items))
+	for _, op := range req.Items {
+		key := lineKey{productID: op.ProductID}
+		if op.VariantID != nil {
+			key.variantID = *op.VariantID
+		}
+		if seen[key] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Aynı ürün birden fazla kez gönderildi",
+				"product_id": op.ProductID,
+				"variant_id": op.VariantID,
+			})
+			return
+		}
+		seen[key] = true
+
+		// quantity gönderilmeyen satır 0 sayılıp silinmemeli; remove olmadan quantity zorunlu
+		if op.Quantity == nil && !op.Remove {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "quantity veya remove gönderilmeli",
+				"product_id": op.ProductID,
+				"variant_id": op.VariantID,
+			})
+			return
+		}
+	}
+
+	// Transaction başlat
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	cartID, err := findOrCreateCart(tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cart oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	// Deadlock'ları önlemek için envanter kilitleri product_id (ve variant_id) sırasıyla alınır
+	sort.Slice(req.Items, func(i, j int) bool {
+		return lineLess(req.Items[i].ProductID, req.Items[i].VariantID, req.Items[j].ProductID, req.Items[j].VariantID)
+	})
+
+	results := make([]cartLineResult, 0, len(req.Items))
+	failed := false
+	for _, op := range req.Items {
+		quantity := 0
+		if !op.Remove {
+			quantity = *op.Quantity
+		}
+
+		result, err := setCartLine(tx, h.holds, userID, cartID, op.ProductID, op.VariantID, quantity)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet güncellenemedi: " + err.Error()})
+			return
+		}
+		if result.Status != cartLineUpdated && result.Status != cartLineRemoved {
+			failed = true
+		}
+		results = append(results, result)
+	}
+
+	if failed {
+		// defer ile rollback yapılır
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Sepet güncellenemedi",
+			"results": results,
+		})
+		return
+	}
+
+	// Transaction commit
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
 func (h *CartHandler) RemoveCartItem(c *gin.Context) {
 	userID := c.GetString("userID")
 	productID, err := strconv.Atoi(c.Param("productId"))
@@ -298,6 +563,11 @@ func (h *CartHandler) RemoveCartItem(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
 		return
 	}
+	variantID, err := variantIDQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz variant ID"})
+		return
+	}
 
 	// Transaction başlat
 	tx, err := database.DB.Begin()
@@ -307,9 +577,9 @@ func (h *CartHandler) RemoveCartItem(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// Kullanıcının cart'ını bul
+	// Kullanıcının cart'ını bul (son aktivite zamanını da güncelle)
 	var cartID int
-	err = tx.QueryRow("SELECT id FROM carts WHERE user_id = $1", userID).Scan(&cartID)
+	err = tx.QueryRow("UPDATE carts SET updated_at = NOW() WHERE user_id = $1 RETURNING id", userID).Scan(&cartID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Sepet bulunamadı"})
 		return
@@ -317,8 +587,8 @@ func (h *CartHandler) RemoveCartItem(c *gin.Context) {
 
 	// Cart item'ı sil
 	result, err := tx.Exec(
-		"DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2",
-		cartID, productID,
+		"DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::int",
+		cartID, productID, variantID,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün sepetten kaldırılamadı: " + err.Error()})
@@ -331,13 +601,18 @@ func (h *CartHandler) RemoveCartItem(c *gin.Context) {
 		return
 	}
 
+	if err = h.holds.sync(tx, userID, productID, variantID, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok tutma kaldırılamadı: " + err.Error()})
+		return
+	}
+
 	// Transaction commit
 	if err = tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"product_id": productID})
+	c.JSON(http.StatusOK, gin.H{"product_id": productID, "variant_id": variantID})
 }
 
 func (h *CartHandler) CreateOrder(c *gin.Context) {
@@ -368,68 +643,88 @@ func (h *CartHandler) CreateOrder(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// EKLEME: Stok kontrolü ve fiyat hesaplamayı ÖNCE yap (overflow/uyuşmazlık kontrolü için)
-	type pricedItem struct {
-		productID  int
-		quantity   int
-		unitPrice  float64
-		totalPrice float64
+	// DÜZELTME: Önce sepet, sonra envanter satırları (product_id sırasıyla) kilitlenir;
+	// böylece aynı sepete yapılan eşzamanlı ekleme/checkout işlemleri sıraya girer
+	var cartID int
+	err = tx.QueryRow("SELECT id FROM carts WHERE user_id = $1 FOR UPDATE", userID).Scan(&cartID)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet alınamadı"})
+		return
 	}
-	var pricedItems []pricedItem
-	var calculatedTotal float64
+	sort.Slice(req.CartItems, func(i, j int) bool {
+		return lineLess(req.CartItems[i].ProductID, req.CartItems[i].VariantID, req.CartItems[j].ProductID, req.CartItems[j].VariantID)
+	})
 
+	// Satın alma limitleri ürün bazındadır; aynı ürünün varyantları birlikte sayılır
+	productQuantities := make(map[int]int, len(req.CartItems))
 	for _, item := range req.CartItems {
-		// stok
-		var availableStock int
-		stockQuery := `
-            SELECT COALESCE((quantity - reserved_quantity), 0) 
-            FROM inventory 
-            WHERE product_id = $1
-        `
-		err = tx.QueryRow(stockQuery, item.ProductID).Scan(&availableStock)
+		productQuantities[item.ProductID] += item.Quantity
+	}
+
+	// EKLEME: Stok kontrolü ve fiyat hesaplamayı ÖNCE yap (overflow/uyuşmazlık kontrolü için)
+	pricedItems := make([]pricedLine, 0, len(req.CartItems))
+	limitChecked := make(map[int]bool, len(productQuantities))
+
+	for _, item := range req.CartItems {
+		if err = validateVariant(tx, item.ProductID, item.VariantID); err != nil {
+			respondVariantError(c, err)
+			return
+		}
+
+		if err = lockStock(tx, item.ProductID, item.VariantID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok kilitlenemedi"})
+			return
+		}
+
+		// stok (kullanıcının kendi hold'u hariç diğer hold'lar düşülür)
+		availableStock, err := availableStockForUser(tx, item.ProductID, item.VariantID, userID)
 		if err != nil || availableStock < item.Quantity {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":      "Yetersiz stok",
 				"product_id": item.ProductID,
+				"variant_id": item.VariantID,
 				"available":  availableStock,
 				"requested":  item.Quantity,
 			})
 			return
 		}
 
-		// fiyat
-		var currentPrice float64
-		priceQuery := "SELECT price FROM products WHERE id = $1 AND is_active = true"
-		err = tx.QueryRow(priceQuery, item.ProductID).Scan(&currentPrice)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün fiyatı alınamadı"})
-			return
+		// EKLEME: Satın alma limiti (geçmiş siparişler dahil)
+		if !limitChecked[item.ProductID] {
+			limitChecked[item.ProductID] = true
+			violation, err := checkPurchaseLimit(tx, userID, item.ProductID, productQuantities[item.ProductID])
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Satın alma limiti kontrol edilemedi"})
+				return
+			}
+			if violation != nil {
+				respondPurchaseLimit(c, violation)
+				return
+			}
 		}
 
-		// 2 ondalıkla yuvarla
-		unit := math.Round(currentPrice*100) / 100
-		line := math.Round(unit*float64(item.Quantity)*100) / 100
-
-		// makul sınır kontrolü (ör: 9,999,999,999.99)
-		if line > 9999999999.99 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Tutar sınırı aşıldı (line)"})
+		// fiyat (varyant fiyatı varsa o geçerlidir)
+		currentPrice, err := unitPriceFor(tx, item.ProductID, item.VariantID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün fiyatı alınamadı"})
 			return
 		}
 
-		calculatedTotal = math.Round((calculatedTotal+line)*100) / 100
-		pricedItems = append(pricedItems, pricedItem{productID: item.ProductID, quantity: item.Quantity, unitPrice: unit, totalPrice: line})
+		pricedItems = append(pricedItems, pricedLine{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			UnitPrice: currentPrice,
+		})
 	}
 
-	// Vergi ve kargo
-	tax := math.Round((calculatedTotal*0.18)*100) / 100
-	shipping := 20.0
-	grandTotal := math.Round((calculatedTotal+tax+shipping)*100) / 100
-
-	// Toplam için sınır kontrolü
-	if grandTotal > 9999999999.99 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Tutar sınırı aşıldı (total)"})
+	// Satır toplamları, vergi ve kargo (sepet özetiyle aynı hesaplama)
+	summary, err := calculateTotals(pricedItems)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	grandTotal := summary.GrandTotal
 
 	// Total amount validation (float tolerance)
 	if math.Abs(grandTotal-req.TotalAmount) > 0.01 {
@@ -457,10 +752,10 @@ func (h *CartHandler) CreateOrder(c *gin.Context) {
 	// Sipariş öğelerini ekle (önceden hesaplanmış, yuvarlanmış değerlerle)
 	for _, it := range pricedItems {
 		orderItemQuery := `
-            INSERT INTO order_items (order_id, product_id, quantity, unit_price, total_price) 
-            VALUES ($1, $2, $3, $4, $5)
+            INSERT INTO order_items (order_id, product_id, variant_id, quantity, unit_price, total_price) 
+            VALUES ($1, $2, $3, $4, $5, $6)
         `
-		_, err = tx.Exec(orderItemQuery, orderID, it.productID, it.quantity, it.unitPrice, it.totalPrice)
+		_, err = tx.Exec(orderItemQuery, orderID, it.ProductID, it.VariantID, it.Quantity, it.UnitPrice, it.LineTotal)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Sipariş öğeleri eklenemedi: " + err.Error()})
 			return
@@ -468,9 +763,7 @@ func (h *CartHandler) CreateOrder(c *gin.Context) {
 	}
 
 	// Sepeti temizle
-	var cartID int
-	err = tx.QueryRow("SELECT id FROM carts WHERE user_id = $1", userID).Scan(&cartID)
-	if err == nil {
+	if cartID > 0 {
 		_, err = tx.Exec("DELETE FROM cart_items WHERE cart_id = $1", cartID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet temizlenemedi"})
@@ -487,17 +780,18 @@ func (h *CartHandler) CreateOrder(c *gin.Context) {
 
 	// EKLEME: Stok rezervasyonu (stok düşürme yerine önce rezerve et)
 	for _, item := range req.CartItems {
-		_, err = tx.Exec(`
-			UPDATE inventory 
-			SET reserved_quantity = reserved_quantity + $1, updated_at = NOW() 
-			WHERE product_id = $2
-		`, item.Quantity, item.ProductID)
-		if err != nil {
+		if err = reserveStock(tx, item.ProductID, item.VariantID, item.Quantity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok rezerve edilemedi"})
 			return
 		}
 	}
 
+	// Sepet hold'ları artık sipariş rezervasyonuna dönüştü
+	if err = releaseCartHolds(tx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok tutma kayıtları temizlenemedi"})
+		return
+	}
+
 	// Transaction commit
 	if err = tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sipariş tamamlanamadı"})
@@ -508,10 +802,182 @@ func (h *CartHandler) CreateOrder(c *gin.Context) {
 		"message":      "Sipariş başarıyla oluşturuldu",
 		"order_id":     orderID,
 		"total_amount": grandTotal,
+		"summary":      summary,
 		"status":       "processing",
 	})
 }
 
+// Sepet satırı işlem sonuçları
+const (
+	cartLineUpdated           = "updated"
+	cartLineRemoved           = "removed"
+	cartLineNotFound          = "not_found"
+	cartLineInsufficientStock = "insufficient_stock"
+	cartLinePurchaseLimit     = "purchase_limit_exceeded"
+	cartLineVariantRequired   = "variant_required"
+)
+
+type cartLineResult struct {
+	ProductID int                     `json:"product_id"`
+	VariantID *int                    `json:"variant_id,omitempty"`
+	Quantity  int                     `json:"quantity"`
+	Status    string                  `json:"status"`
+	Available int                     `json:"available"`
+	Limit     *purchaseLimitViolation `json:"limit,omitempty"`
+}
+
+// applyCartItemWarnings sepete eklenme anından bu yana değişen fiyat ve
+// stok durumlarını satıra uyarı olarak işler
+func applyCartItemWarnings(item *models.CartItem) {
+	if item.Product == nil {
+		return
+	}
+
+	unitPrice := item.Product.EffectivePrice
+	if item.Variant != nil {
+		unitPrice = item.Variant.EffectivePrice
+	}
+	item.PriceChange = math.Round((unitPrice-item.PriceAtAdd)*100) / 100
+	switch {
+	case item.PriceChange > 0:
+		item.Warnings = append(item.Warnings, models.CartWarningPriceIncreased)
+	case item.PriceChange < 0:
+		item.Warnings = append(item.Warnings, models.CartWarningPriceDecreased)
+	}
+
+	available := 0
+	if item.AvailableStock != nil {
+		available = *item.AvailableStock
+	}
+	variantInactive := item.Variant != nil && !item.Variant.IsActive
+	archived := item.Product.DeletedAt != nil
+	if !item.Product.IsActive || archived || variantInactive || available <= 0 {
+		item.Warnings = append(item.Warnings, models.CartWarningUnavailable)
+	} else if item.Quantity > available {
+		item.Warnings = append(item.Warnings, models.CartWarningExceedsStock)
+	}
+}
+
+// findOrCreateCart kullanıcının sepetini transaction içinde bulur, yoksa oluşturur.
+// Upsert sepet satırını transaction sonuna kadar kilitler; aynı kullanıcının
+// eşzamanlı sepet işlemleri böylece sıraya girer. Sepetin updated_at alanı son
+// aktivite zamanı olarak güncellenir (terk edilmiş sepet tespiti için).
+func findOrCreateCart(tx *sql.Tx, userID string) (int, error) {
+	var cartID int
+	err := tx.QueryRow(`
+		INSERT INTO carts (user_id, created_at, updated_at) VALUES ($1, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET updated_at = NOW()
+		RETURNING id
+	`, userID).Scan(&cartID)
+	return cartID, err
+}
+
+// lineLess sepet satırlarını kilit sırası için (product_id, variant_id) çiftine göre karşılaştırır
+func lineLess(productA int, variantA *int, productB int, variantB *int) bool {
+	if productA != productB {
+		return productA < productB
+	}
+	a, b := 0, 0
+	if variantA != nil {
+		a = *variantA
+	}
+	if variantB != nil {
+		b = *variantB
+	}
+	return a < b
+}
+
+// setCartLine sepet satırının miktarını mutlak değere ayarlar; 0 satırı kaldırır.
+// Stok yetersizliği veya ürün bulunamaması hata değil, sonuç durumu olarak döner.
+func setCartLine(tx *sql.Tx, holds cartHolds, userID string, cartID, productID int, variantID *int, quantity int) (cartLineResult, error) {
+	result := cartLineResult{ProductID: productID, VariantID: variantID, Quantity: quantity}
+
+	if quantity == 0 {
+		_, err := tx.Exec(
+			"DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::int",
+			cartID, productID, variantID,
+		)
+		if err != nil {
+			return result, err
+		}
+		if err := holds.sync(tx, userID, productID, variantID, 0); err != nil {
+			return result, err
+		}
+		result.Status = cartLineRemoved
+		return result, nil
+	}
+
+	var productExists bool
+	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_active = true AND deleted_at IS NULL)", productID).Scan(&productExists)
+	if err != nil {
+		return result, err
+	}
+	if !productExists {
+		result.Status = cartLineNotFound
+		return result, nil
+	}
+
+	switch err = validateVariant(tx, productID, variantID); err {
+	case nil:
+	case errVariantRequired:
+		result.Status = cartLineVariantRequired
+		return result, nil
+	case errVariantNotFound:
+		result.Status = cartLineNotFound
+		return result, nil
+	default:
+		return result, err
+	}
+
+	if err = lockStock(tx, productID, variantID); err != nil {
+		return result, err
+	}
+
+	result.Available, err = availableStockForUser(tx, productID, variantID, userID)
+	if err != nil {
+		return result, err
+	}
+	if result.Available < quantity {
+		result.Status = cartLineInsufficientStock
+		return result, nil
+	}
+
+	// Limit ürün bazında: aynı ürünün diğer varyant satırları da sayılır
+	otherVariants, err := otherVariantsInCart(tx, cartID, productID, variantID)
+	if err != nil {
+		return result, err
+	}
+	result.Limit, err = checkPurchaseLimit(tx, userID, productID, otherVariants+quantity)
+	if err != nil {
+		return result, err
+	}
+	if result.Limit != nil {
+		result.Status = cartLinePurchaseLimit
+		return result, nil
+	}
+
+	unitPrice, err := unitPriceFor(tx, productID, variantID)
+	if err != nil {
+		return result, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO cart_items (cart_id, product_id, variant_id, quantity, price_at_add, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (cart_id, product_id, (COALESCE(variant_id, 0))) DO UPDATE SET quantity = EXCLUDED.quantity
+	`, cartID, productID, variantID, quantity, unitPrice)
+	if err != nil {
+		return result, err
+	}
+
+	if err := holds.sync(tx, userID, productID, variantID, quantity); err != nil {
+		return result, err
+	}
+
+	result.Status = cartLineUpdated
+	return result, nil
+}
+
 // EKLEME: Supabase client ile alternatif cart items methodu (performans karşılaştırması için)
 func (h *CartHandler) GetCartItemsWithSupabase(c *gin.Context) {
 	userID := c.GetString("userID")
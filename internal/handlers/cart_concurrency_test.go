@@ -0,0 +1,241 @@
+// ========================================
+// internal/handlers/cart_concurrency_test.go - EŞZAMANLI SEPET İŞLEMLERİ TESTİ
+// ========================================
+package handlers
+
+import (
+	"bytes"
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const (
+	concurrentCartUsers       = 6
+	concurrentCartOpsPerUser  = 40
+	concurrentCartStock       = 7
+	concurrentCartMaxQuantity = 3
+)
+
+// TestCartConcurrentMutations sepet handler'larını gerçek bir Postgres'e karşı paralel çalıştırır ve
+// her kullanıcı için tek sepet, (product_id, variant_id) başına tek satır ve sepetlerdeki toplam
+// miktarın mevcut stoku aşmaması değişmezlerini kontrol eder. TEST_DATABASE_URL tanımlı değilse atlanır;
+// veritabanında uygulamanın temel şeması (products, inventory, carts, cart_items) bulunmalıdır.
+// Çalıştırma adımları README'deki "Testler" bölümündedir.
+func TestCartConcurrentMutations(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL tanımlı değil; eşzamanlı sepet testi atlandı (bkz. README, Testler)")
+	}
+	if err := database.Connect(dsn); err != nil {
+		t.Fatalf("veritabanına bağlanılamadı: %v", err)
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("migration'lar uygulanamadı: %v", err)
+	}
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	stocked := createCartTestProduct(t, "stocked-"+suffix, intPtr(concurrentCartStock))
+	unstocked := createCartTestProduct(t, "unstocked-"+suffix, nil)
+	productIDs := []int{stocked, unstocked}
+
+	users := make([]string, concurrentCartUsers)
+	for i := range users {
+		if err := database.DB.QueryRow("SELECT gen_random_uuid()::text").Scan(&users[i]); err != nil {
+			t.Fatalf("kullanıcı id'si üretilemedi: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		database.DB.Exec("DELETE FROM cart_holds WHERE user_id = ANY($1::uuid[])", pq.Array(users))
+		database.DB.Exec("DELETE FROM cart_items WHERE cart_id IN (SELECT id FROM carts WHERE user_id = ANY($1::uuid[]))", pq.Array(users))
+		database.DB.Exec("DELETE FROM carts WHERE user_id = ANY($1::uuid[])", pq.Array(users))
+		database.DB.Exec("DELETE FROM inventory WHERE product_id = ANY($1)", pq.Array(productIDs))
+		database.DB.Exec("DELETE FROM products WHERE id = ANY($1)", pq.Array(productIDs))
+	})
+
+	// Sepet tablosu kullanıcıları auth şemasına bağlıyorsa rastgele kullanıcılarla test yapılamaz
+	if _, err := database.DB.Exec("INSERT INTO carts (user_id, created_at, updated_at) VALUES ($1, NOW(), NOW())", users[0]); err != nil {
+		if isForeignKeyViolation(err) {
+			t.Skipf("carts.user_id bir kullanıcı tablosuna bağlı; test kullanıcıları oluşturulamıyor: %v", err)
+		}
+		t.Fatalf("test sepeti oluşturulamadı: %v", err)
+	}
+
+	// Hold'lar açıkken diğer kullanıcıların sepetleri de stoktan düşülür
+	router := newCartTestRouter(NewCartHandler(&config.Config{CartHoldsEnabled: true, CartHoldTTL: time.Hour}))
+
+	var wg sync.WaitGroup
+	for i, userID := range users {
+		wg.Add(1)
+		go func(userID string, seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for n := 0; n < concurrentCartOpsPerUser; n++ {
+				productID := productIDs[rng.Intn(len(productIDs))]
+				quantity := rng.Intn(concurrentCartMaxQuantity) + 1
+
+				var method, path string
+				var body interface{}
+				switch rng.Intn(3) {
+				case 0:
+					method, path = http.MethodPost, "/cart/items"
+					body = gin.H{"product_id": productID, "quantity": quantity}
+				case 1:
+					method, path = http.MethodPut, fmt.Sprintf("/cart/items/%d", productID)
+					body = gin.H{"quantity": rng.Intn(concurrentCartMaxQuantity + 1)}
+				default:
+					method, path = http.MethodPatch, "/cart/items"
+					body = gin.H{"items": []gin.H{
+						{"product_id": stocked, "quantity": quantity},
+						{"product_id": unstocked, "remove": true},
+					}}
+				}
+
+				status, response := serveCartTestRequest(router, userID, method, path, body)
+				// Yetersiz stok 400 döner; 500 deadlock veya unique ihlali gibi yarış hatası demektir
+				if status >= http.StatusInternalServerError {
+					t.Errorf("%s %s: beklenmeyen durum %d: %s", method, path, status, response)
+				}
+			}
+		}(userID, int64(i+1))
+	}
+	wg.Wait()
+
+	var duplicateCarts int
+	if err := database.DB.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT user_id FROM carts WHERE user_id = ANY($1::uuid[]) GROUP BY user_id HAVING COUNT(*) > 1
+		) d
+	`, pq.Array(users)).Scan(&duplicateCarts); err != nil {
+		t.Fatalf("sepetler sayılamadı: %v", err)
+	}
+	if duplicateCarts > 0 {
+		t.Errorf("%d kullanıcının birden fazla sepeti var", duplicateCarts)
+	}
+
+	var duplicateLines int
+	if err := database.DB.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT ci.cart_id, ci.product_id, COALESCE(ci.variant_id, 0)
+			FROM cart_items ci JOIN carts ca ON ca.id = ci.cart_id
+			WHERE ca.user_id = ANY($1::uuid[])
+			GROUP BY ci.cart_id, ci.product_id, COALESCE(ci.variant_id, 0)
+			HAVING COUNT(*) > 1
+		) d
+	`, pq.Array(users)).Scan(&duplicateLines); err != nil {
+		t.Fatalf("sepet satırları sayılamadı: %v", err)
+	}
+	if duplicateLines > 0 {
+		t.Errorf("%d (sepet, ürün, varyant) için birden fazla satır var", duplicateLines)
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT p.id, COALESCE(i.quantity - i.reserved_quantity, 0),
+		       COALESCE((
+		           SELECT SUM(ci.quantity) FROM cart_items ci JOIN carts ca ON ca.id = ci.cart_id
+		           WHERE ci.product_id = p.id AND ca.user_id = ANY($2::uuid[])
+		       ), 0),
+		       COALESCE((
+		           SELECT MAX(ci.quantity) FROM cart_items ci JOIN carts ca ON ca.id = ci.cart_id
+		           WHERE ci.product_id = p.id AND ca.user_id = ANY($2::uuid[])
+		       ), 0)
+		FROM products p
+		LEFT JOIN inventory i ON i.product_id = p.id
+		WHERE p.id = ANY($1)
+	`, pq.Array(productIDs), pq.Array(users))
+	if err != nil {
+		t.Fatalf("stoklar alınamadı: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var productID, available, inCarts, maxLine int
+		if err := rows.Scan(&productID, &available, &inCarts, &maxLine); err != nil {
+			t.Fatalf("stok okunamadı: %v", err)
+		}
+		if maxLine > available {
+			t.Errorf("ürün %d: sepet satırı (%d) mevcut stoku (%d) aşıyor", productID, maxLine, available)
+		}
+		if inCarts > available {
+			t.Errorf("ürün %d: sepetlerdeki toplam miktar (%d) mevcut stoku (%d) aşıyor", productID, inCarts, available)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("stoklar okunamadı: %v", err)
+	}
+}
+
+// createCartTestProduct aktif bir test ürünü oluşturur; stock nil ise envanter kaydı eklenmez.
+// Yalnızca sepet güvenliğinin (user-031) dayandığı temel sütunlar yazılır; sonraki
+// migration'ların eklediği zorunlu slug sütunu varsa o da doldurulur.
+func createCartTestProduct(t *testing.T, slug string, stock *int) int {
+	t.Helper()
+	var hasSlug bool
+	if err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM information_schema.columns WHERE table_name = 'products' AND column_name = 'slug')
+	`).Scan(&hasSlug); err != nil {
+		t.Fatalf("products sütunları okunamadı: %v", err)
+	}
+	columns, values := "", ""
+	if hasSlug {
+		columns, values = ", slug", ", $1"
+	}
+
+	var productID int
+	err := database.DB.QueryRow(`
+		INSERT INTO products (title, description, price, image, category, sku, rating, rating_count, is_active`+columns+`, created_at, updated_at)
+		VALUES ($1, '', 10, '', '', $1, 0, 0, true`+values+`, NOW(), NOW())
+		RETURNING id
+	`, slug).Scan(&productID)
+	if err != nil {
+		t.Fatalf("test ürünü oluşturulamadı: %v", err)
+	}
+	if stock != nil {
+		if _, err := database.DB.Exec(`
+			INSERT INTO inventory (product_id, quantity, reserved_quantity, min_stock_level, max_stock_level, cost_price, updated_at)
+			VALUES ($1, $2, 0, 0, 0, 0, NOW())
+		`, productID, *stock); err != nil {
+			t.Fatalf("test envanteri oluşturulamadı: %v", err)
+		}
+	}
+	return productID
+}
+
+// newCartTestRouter Auth middleware yerine kullanıcıyı X-Test-User başlığından alır
+func newCartTestRouter(h *CartHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-Test-User"))
+		c.Next()
+	})
+	router.POST("/cart/items", h.AddOrUpdateCartItem)
+	router.PATCH("/cart/items", h.BulkUpdateCartItems)
+	router.PUT("/cart/items/:productId", h.SetCartItemQuantity)
+	return router
+}
+
+func serveCartTestRequest(router *gin.Engine, userID, method, path string, body interface{}) (int, string) {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", userID)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code, recorder.Body.String()
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,107 @@
+// ========================================
+// internal/handlers/cart_holds.go - SEPET STOK TUTMA (SOFT HOLD)
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/config"
+	"time"
+)
+
+// cartHolds sepete eklenen ürünler için süreli stok tutma ayarları.
+// Aktif (süresi dolmamış) hold'lar diğer kullanıcılar için mevcut stoktan düşülür.
+type cartHolds struct {
+	enabled bool
+	ttl     time.Duration
+}
+
+func newCartHolds(cfg *config.Config) cartHolds {
+	return cartHolds{
+		enabled: cfg.CartHoldsEnabled,
+		ttl:     cfg.CartHoldTTL,
+	}
+}
+
+// sync kullanıcının ürün (ve varsa varyant) için tuttuğu miktarı sepetteki miktara
+// eşitler ve diğer aktif hold'larının süresini uzatır. Miktar 0 ise hold kaldırılır.
+func (ch cartHolds) sync(tx *sql.Tx, userID string, productID int, variantID *int, quantity int) error {
+	if !ch.enabled {
+		return nil
+	}
+
+	if quantity <= 0 {
+		_, err := tx.Exec(
+			"DELETE FROM cart_holds WHERE user_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::int",
+			userID, productID, variantID,
+		)
+		if err != nil {
+			return err
+		}
+	} else {
+		_, err := tx.Exec(`
+			INSERT INTO cart_holds (user_id, product_id, variant_id, quantity, expires_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5), NOW(), NOW())
+			ON CONFLICT (user_id, product_id, (COALESCE(variant_id, 0))) DO UPDATE SET
+			  quantity = EXCLUDED.quantity,
+			  expires_at = EXCLUDED.expires_at,
+			  updated_at = NOW()
+		`, userID, productID, variantID, quantity, ch.ttl.Seconds())
+		if err != nil {
+			return err
+		}
+	}
+
+	return ch.extend(tx, userID)
+}
+
+// extend kullanıcının süresi dolmamış tüm hold'larını TTL kadar uzatır
+func (ch cartHolds) extend(q execer, userID string) error {
+	if !ch.enabled {
+		return nil
+	}
+
+	_, err := q.Exec(`
+		UPDATE cart_holds
+		SET expires_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE user_id = $1 AND expires_at > NOW()
+	`, userID, ch.ttl.Seconds())
+	return err
+}
+
+// execer hem *sql.DB hem *sql.Tx ile çalışan yardımcılar için
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// availableStockForUser envanterdeki (varyant seçiliyse varyant envanterindeki)
+// serbest stoktan diğer kullanıcıların aktif hold'larını düşerek döndürür.
+// userID boşsa tüm aktif hold'lar düşülür. Envanter kaydı yoksa stok 0 kabul edilir.
+func availableStockForUser(q queryRower, productID int, variantID *int, userID string) (int, error) {
+	var holder interface{}
+	if userID != "" {
+		holder = userID
+	}
+
+	var available int
+	err := q.QueryRow(`
+		SELECT
+			CASE WHEN $2::int IS NULL
+				THEN COALESCE((SELECT quantity - reserved_quantity FROM inventory WHERE product_id = $1), 0)
+				ELSE COALESCE((SELECT quantity - reserved_quantity FROM variant_inventory WHERE variant_id = $2::int), 0)
+			END
+			- COALESCE((
+				SELECT SUM(quantity) FROM cart_holds
+				WHERE product_id = $1 AND variant_id IS NOT DISTINCT FROM $2::int AND expires_at > NOW()
+				  AND ($3::uuid IS NULL OR user_id <> $3::uuid)
+			), 0)
+	`, productID, variantID, holder).Scan(&available)
+	return available, err
+}
+
+// releaseCartHolds kullanıcının tüm hold'larını kaldırır (checkout sonrası
+// stok artık sipariş rezervasyonu olarak tutulduğu için)
+func releaseCartHolds(q execer, userID string) error {
+	_, err := q.Exec("DELETE FROM cart_holds WHERE user_id = $1", userID)
+	return err
+}
@@ -0,0 +1,578 @@
+// ========================================
+// internal/handlers/category.go - HİYERARŞİK KATEGORİ AĞACI
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errCategoryNotFound = errors.New("Kategori bulunamadı")
+	errCategoryCycle    = errors.New("Kategori kendi alt kategorisinin altına taşınamaz")
+)
+
+type CategoryHandler struct {
+	cfg *config.Config
+}
+
+func NewCategoryHandler(cfg *config.Config) *CategoryHandler {
+	return &CategoryHandler{cfg: cfg}
+}
+
+// categorySubtreeSQL rootCond koşulunu sağlayan kategorilerin ve tüm alt
+// kategorilerinin id'lerini döndüren alt sorguyu üretir. UNION (ALL değil)
+// bozuk veride döngü olsa bile özyinelemenin sonlanmasını garanti eder.
+func categorySubtreeSQL(rootCond string) string {
+	return `WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE ` + rootCond + `
+			UNION
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		) SELECT id FROM subtree`
+}
+
+// appendCategoryFilter ürün sorgusuna kategori filtresini ekler; seçilen
+// kategorinin tüm alt kategorilerindeki ürünler de sonuca dahildir.
+// category slug veya ad olabilir; henüz bir kategoriye bağlanmamış eski ürünler
+// için products.category metniyle birebir eşleşme de korunur.
+func appendCategoryFilter(query string, args []interface{}, categoryID int, category string) (string, []interface{}) {
+	if categoryID > 0 {
+		args = append(args, categoryID)
+		placeholder := "$" + strconv.Itoa(len(args))
+		query += " AND p.category_id IN (" + categorySubtreeSQL("id = "+placeholder) + ")"
+		return query, args
+	}
+	if category != "" {
+		args = append(args, category)
+		placeholder := "$" + strconv.Itoa(len(args))
+		query += " AND (p.category_id IN (" + categorySubtreeSQL("slug = "+placeholder+" OR name = "+placeholder) + ")" +
+			" OR (p.category_id IS NULL AND p.category = " + placeholder + "))"
+	}
+	return query, args
+}
+
+// categoryIDQuery ?category_id= parametresini okur; boşsa 0 döner
+func categoryIDQuery(c *gin.Context) (int, error) {
+	raw := c.Query("category_id")
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, errors.New("Geçersiz category_id")
+	}
+	return id, nil
+}
+
+// resolveProductCategory ürün için kategori bağlantısını belirler. categoryID
+// verilmişse o kategori kullanılır ve kategori metni adıyla eşitlenir; yalnızca
+// ad verilmişse ad veya slug ile eşleşen kategori aranır, yoksa metin olduğu
+// gibi (bağlantısız) kalır.
+func resolveProductCategory(q queryRower, categoryID *int, name string) (*int, string, error) {
+	if categoryID != nil && *categoryID > 0 {
+		var resolved string
+		err := q.QueryRow("SELECT name FROM categories WHERE id = $1", *categoryID).Scan(&resolved)
+		if err == sql.ErrNoRows {
+			return nil, "", errCategoryNotFound
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return categoryID, resolved, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", nil
+	}
+	var id int
+	var resolved string
+	err := q.QueryRow(
+		"SELECT id, name FROM categories WHERE LOWER(name) = LOWER($1) OR slug = $2 ORDER BY (LOWER(name) = LOWER($1)) DESC LIMIT 1",
+		name, slugify(name),
+	).Scan(&id, &resolved)
+	if err == sql.ErrNoRows {
+		return nil, name, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return &id, resolved, nil
+}
+
+// loadCategories tüm kategorileri, her birine doğrudan bağlı aktif ürün sayısıyla döner
+func loadCategories(includeInactive bool) ([]models.Category, error) {
+	query := `
+		SELECT c.id, c.parent_id, c.name, c.slug, c.description, c.image, c.sort_order, c.is_active,
+		       c.created_at, c.updated_at,
+		       (SELECT COUNT(*) FROM products p WHERE p.category_id = c.id AND p.is_active = true AND p.deleted_at IS NULL)
+		FROM categories c
+	`
+	if !includeInactive {
+		query += " WHERE c.is_active = true"
+	}
+	query += " ORDER BY c.sort_order, c.name"
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(
+			&category.ID, &category.ParentID, &category.Name, &category.Slug, &category.Description,
+			&category.Image, &category.SortOrder, &category.IsActive, &category.CreatedAt, &category.UpdatedAt,
+			&category.ProductCount,
+		); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// buildCategoryTree düz listeden ağaç kurar; ProductCount alt ağaçtaki toplamı
+// gösterir. Ebeveyni listede olmayan (ör: pasif ebeveyn) kategoriler köke çıkmaz, atlanır.
+func buildCategoryTree(categories []models.Category) []models.Category {
+	children := make(map[int][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var attach func(node *models.Category, depth int)
+	attach = func(node *models.Category, depth int) {
+		// Bozuk veride sonsuz özyinelemeye karşı derinlik sınırı
+		if depth > 32 {
+			return
+		}
+		node.Children = children[node.ID]
+		for i := range node.Children {
+			attach(&node.Children[i], depth+1)
+			node.ProductCount += node.Children[i].ProductCount
+		}
+	}
+	for i := range roots {
+		attach(&roots[i], 0)
+	}
+	if roots == nil {
+		roots = []models.Category{}
+	}
+	return roots
+}
+
+// GetCategoryTree kategori ağacını döner; ?include_inactive=true pasif kategorileri de içerir
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	categories, err := loadCategories(c.Query("include_inactive") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategoriler alınamadı: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"categories": buildCategoryTree(categories)})
+}
+
+// GetCategory slug veya id ile tek kategori; alt kategoriler ve kökten itibaren breadcrumb ile
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	key := c.Param("slug")
+
+	categories, err := loadCategories(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategoriler alınamadı: " + err.Error()})
+		return
+	}
+
+	byID := make(map[int]models.Category, len(categories))
+	var found *models.Category
+	for i, category := range categories {
+		byID[category.ID] = category
+		if category.Slug == key || strconv.Itoa(category.ID) == key {
+			found = &categories[i]
+		}
+	}
+	if found == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errCategoryNotFound.Error()})
+		return
+	}
+
+	var node models.Category
+	for _, root := range buildCategoryTree(categories) {
+		if n := findCategoryNode(root, found.ID); n != nil {
+			node = *n
+			break
+		}
+	}
+	if node.ID == 0 {
+		// Ebeveyni pasif olan kategori ağaçta yer almaz; tek başına döndür
+		node = *found
+	}
+
+	breadcrumb := []gin.H{}
+	seen := map[int]bool{}
+	for current, ok := byID[found.ID]; ok && !seen[current.ID]; {
+		seen[current.ID] = true
+		breadcrumb = append([]gin.H{{"id": current.ID, "name": current.Name, "slug": current.Slug}}, breadcrumb...)
+		if current.ParentID == nil {
+			break
+		}
+		current, ok = byID[*current.ParentID]
+	}
+
+	// EKLEME: Kategorideki ürünlerin kullanabileceği özellikler (üst kategorilerden gelenler dahil)
+	attributes, err := loadCategoryAttributes(database.DB, found.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori özellikleri alınamadı: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": node, "breadcrumb": breadcrumb, "attributes": attributes})
+}
+
+func findCategoryNode(node models.Category, id int) *models.Category {
+	if node.ID == id {
+		return &node
+	}
+	for _, child := range node.Children {
+		if found := findCategoryNode(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// CreateCategory yeni kategori ekler; slug verilmezse addan üretilir ve
+// çakışma durumunda sonuna -2, -3... eklenir
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req models.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori adı gerekli"})
+		return
+	}
+
+	if req.ParentID != nil {
+		var exists bool
+		if err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", *req.ParentID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Üst kategori bulunamadı"})
+			return
+		}
+	}
+
+	slug := slugify(req.Slug)
+	explicitSlug := slug != ""
+	if !explicitSlug {
+		slug = slugify(name)
+	}
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçerli bir slug üretilemedi"})
+		return
+	}
+	if !explicitSlug {
+		var err error
+		slug, err = uniqueCategorySlug(database.DB, slug, 0)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Slug üretilemedi: " + err.Error()})
+			return
+		}
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	category := models.Category{
+		ParentID:    req.ParentID,
+		Name:        name,
+		Slug:        slug,
+		Description: req.Description,
+		Image:       req.Image,
+		SortOrder:   req.SortOrder,
+		IsActive:    isActive,
+	}
+	err := database.DB.QueryRow(`
+		INSERT INTO categories (parent_id, name, slug, description, image, sort_order, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, category.ParentID, category.Name, category.Slug, category.Description, category.Image,
+		category.SortOrder, category.IsActive,
+	).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu slug başka bir kategoride kullanılıyor", "slug": slug})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"category": category})
+}
+
+// UpdateCategory kategoriyi günceller. Üst kategori değişikliğinde döngü
+// oluşturan taşımalar reddedilir; ad değişirse bağlı ürünlerin kategori metni de güncellenir.
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kategori ID"})
+		return
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	var category models.Category
+	err = tx.QueryRow(`
+		SELECT id, parent_id, name, slug, description, image, sort_order, is_active, created_at, updated_at
+		FROM categories WHERE id = $1 FOR UPDATE
+	`, categoryID).Scan(
+		&category.ID, &category.ParentID, &category.Name, &category.Slug, &category.Description,
+		&category.Image, &category.SortOrder, &category.IsActive, &category.CreatedAt, &category.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": errCategoryNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori alınamadı: " + err.Error()})
+		return
+	}
+	oldName := category.Name
+
+	if req.MakeRoot {
+		category.ParentID = nil
+	} else if req.ParentID != nil {
+		if err := checkCategoryParent(tx, categoryID, *req.ParentID); err != nil {
+			if errors.Is(err, errCategoryNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Üst kategori bulunamadı"})
+				return
+			}
+			if errors.Is(err, errCategoryCycle) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Üst kategori kontrol edilemedi: " + err.Error()})
+			return
+		}
+		category.ParentID = req.ParentID
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori adı gerekli"})
+			return
+		}
+		category.Name = name
+	}
+	if req.Slug != nil {
+		slug := slugify(*req.Slug)
+		if slug == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz slug"})
+			return
+		}
+		category.Slug = slug
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+	if req.Image != nil {
+		category.Image = *req.Image
+	}
+	if req.SortOrder != nil {
+		category.SortOrder = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
+
+	err = tx.QueryRow(`
+		UPDATE categories
+		SET parent_id = $1, name = $2, slug = $3, description = $4, image = $5, sort_order = $6,
+		    is_active = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING updated_at
+	`, category.ParentID, category.Name, category.Slug, category.Description, category.Image,
+		category.SortOrder, category.IsActive, categoryID,
+	).Scan(&category.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu slug başka bir kategoride kullanılıyor", "slug": category.Slug})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori güncellenemedi: " + err.Error()})
+		return
+	}
+
+	if category.Name != oldName {
+		if _, err := tx.Exec(
+			"UPDATE products SET category = $1, updated_at = NOW() WHERE category_id = $2",
+			category.Name, categoryID,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün kategorileri güncellenemedi: " + err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// DeleteCategory alt kategorisi veya bağlı ürünü olmayan kategoriyi siler
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kategori ID"})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", categoryID).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori alınamadı: " + err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": errCategoryNotFound.Error()})
+		return
+	}
+
+	var childCount, productCount int
+	err = tx.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM categories WHERE parent_id = $1),
+		       (SELECT COUNT(*) FROM products WHERE category_id = $1)
+	`, categoryID).Scan(&childCount, &productCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori kontrol edilemedi: " + err.Error()})
+		return
+	}
+	if childCount > 0 || productCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "Alt kategorisi veya ürünü olan kategori silinemez",
+			"child_count":   childCount,
+			"product_count": productCount,
+		})
+		return
+	}
+
+	if _, err := tx.Exec("DELETE FROM categories WHERE id = $1", categoryID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori silinemedi: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kategori silindi"})
+}
+
+// checkCategoryParent parentID'nin var olduğunu ve categoryID'nin alt ağacında olmadığını doğrular
+func checkCategoryParent(q queryRower, categoryID, parentID int) error {
+	var parentExists, inSubtree bool
+	err := q.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM categories WHERE id = $2),
+		       $2 IN (`+categorySubtreeSQL("id = $1")+`)
+	`, categoryID, parentID).Scan(&parentExists, &inSubtree)
+	if err != nil {
+		return err
+	}
+	if !parentExists {
+		return errCategoryNotFound
+	}
+	if inSubtree {
+		return errCategoryCycle
+	}
+	return nil
+}
+
+// uniqueCategorySlug base slug alınmışsa base-2, base-3... şeklinde boş olanı bulur
+func uniqueCategorySlug(q queryer, base string, excludeID int) (string, error) {
+	rows, err := q.Query(
+		"SELECT slug FROM categories WHERE (slug = $1 OR slug LIKE $1 || '-%') AND id <> $2",
+		base, excludeID,
+	)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", err
+		}
+		taken[slug] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	if !taken[base] {
+		return base, nil
+	}
+	suffixes := make([]int, 0, len(taken))
+	for slug := range taken {
+		if n, err := strconv.Atoi(strings.TrimPrefix(slug, base+"-")); err == nil {
+			suffixes = append(suffixes, n)
+		}
+	}
+	sort.Ints(suffixes)
+	next := 2
+	for _, n := range suffixes {
+		if n == next {
+			next++
+		}
+	}
+	return base + "-" + strconv.Itoa(next), nil
+}
+
+func respondCategoryError(c *gin.Context, err error) {
+	if errors.Is(err, errCategoryNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori alınamadı: " + err.Error()})
+}
@@ -0,0 +1,75 @@
+// ========================================
+// internal/handlers/feed.go - ÜRÜN FEED ENDPOINT'LERİ (GOOGLE MERCHANT, JSON KATALOG)
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/feed"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedHandler struct {
+	cfg   *config.Config
+	cache *feed.Cache
+}
+
+func NewFeedHandler(cfg *config.Config, cache *feed.Cache) *FeedHandler {
+	return &FeedHandler{cfg: cfg, cache: cache}
+}
+
+// GetFeed önbellekteki feed'i döner: google.xml, google.tsv veya catalog.json.
+// ETag / If-None-Match desteklenir; platformlar değişmeyen feed'i tekrar indirmez.
+func (h *FeedHandler) GetFeed(c *gin.Context) {
+	h.serveSnapshot(c, c.Param("file"))
+}
+
+// GetSitemap ana sayfa, aktif kategoriler ve ürünlerden oluşan sitemap.xml; feed'lerle
+// aynı önbellekten ve aynı yenileme aralığıyla üretilir
+func (h *FeedHandler) GetSitemap(c *gin.Context) {
+	h.serveSnapshot(c, feed.FormatSitemap)
+}
+
+func (h *FeedHandler) serveSnapshot(c *gin.Context, format string) {
+	snapshot, err := h.cache.Get(c.Request.Context(), format)
+	if err != nil {
+		if err == feed.ErrUnknownFormat {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Feed bulunamadı (google.xml, google.tsv, catalog.json, sitemap.xml)"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Feed üretilemedi: " + err.Error()})
+		return
+	}
+
+	c.Header("ETag", snapshot.ETag)
+	c.Header("Last-Modified", snapshot.GeneratedAt.Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(h.cfg.FeedRefreshInterval/time.Second)))
+	c.Header("X-Feed-Item-Count", strconv.Itoa(snapshot.Count))
+	if c.GetHeader("If-None-Match") == snapshot.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, snapshot.ContentType, snapshot.Body)
+}
+
+// RefreshFeeds feed'leri bir sonraki periyodik üretimi beklemeden yeniden üretir
+func (h *FeedHandler) RefreshFeeds(c *gin.Context) {
+	if err := h.cache.Refresh(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Feed üretilemedi: " + err.Error()})
+		return
+	}
+	snapshot, err := h.cache.Get(c.Request.Context(), feed.FormatJSON)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Feed alınamadı: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Feed'ler yeniden üretildi",
+		"count":        snapshot.Count,
+		"generated_at": snapshot.GeneratedAt,
+	})
+}
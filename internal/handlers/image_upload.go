@@ -0,0 +1,68 @@
+// ========================================
+// internal/handlers/image_upload.go - İŞLENMİŞ GÖRSEL YÜKLEME
+// ========================================
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"ecommerce-backend/internal/imaging"
+	"ecommerce-backend/internal/storage"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// primaryRendition ana URL olarak kullanılan varyant
+const primaryRendition = "large"
+
+// uploadedImage storage'a yüklenmiş görsel varyantları
+type uploadedImage struct {
+	URL      string            // ana (large) varyantın URL'i
+	Path     string            // ana varyantın nesne yolu
+	Variants map[string]string // varyant adı -> URL
+	Paths    []string          // yüklenen tüm nesne yolları
+}
+
+// uploadRenditions işlenmiş varyantları bucket içinde prefix/{ad}{uzantı} yollarına yükler.
+// Herhangi biri yüklenemezse o ana kadar yüklenenler silinir.
+func uploadRenditions(ctx context.Context, store storage.Storage, bucket, prefix string, result *imaging.Result) (*uploadedImage, error) {
+	uploaded := &uploadedImage{Variants: make(map[string]string, len(result.Renditions))}
+	for _, rendition := range result.Renditions {
+		objectPath := prefix + "/" + rendition.Name + rendition.Ext
+		if err := store.Put(ctx, bucket, objectPath, rendition.ContentType, bytes.NewReader(rendition.Data)); err != nil {
+			deleteObjects(ctx, store, bucket, uploaded.Paths)
+			return nil, err
+		}
+		url := store.PublicURL(bucket, objectPath)
+		uploaded.Paths = append(uploaded.Paths, objectPath)
+		uploaded.Variants[rendition.Name] = url
+		if rendition.Name == primaryRendition || uploaded.URL == "" {
+			uploaded.URL, uploaded.Path = url, objectPath
+		}
+	}
+	return uploaded, nil
+}
+
+// deleteObjects nesneleri best-effort siler; ilk hatayı döndürür
+func deleteObjects(ctx context.Context, store storage.Storage, bucket string, paths []string) error {
+	var firstErr error
+	for _, objectPath := range paths {
+		if err := store.Delete(ctx, bucket, objectPath); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// respondImageError görsel işleme hatalarını HTTP yanıtına çevirir
+func respondImageError(c *gin.Context, err error) {
+	switch err {
+	case imaging.ErrFileTooLarge:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dosya boyutu çok büyük (max 5MB)"})
+	case imaging.ErrNotImage, imaging.ErrUnsupportedFormat, imaging.ErrDimensionsTooBig:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Görsel işlenemedi: " + err.Error()})
+	}
+}
@@ -0,0 +1,91 @@
+// ========================================
+// internal/handlers/pricing.go - SEPET / SİPARİŞ TUTAR HESAPLAMA
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/models"
+	"errors"
+	"math"
+)
+
+// Vergi, kargo ve tutar sınırları; sepet özeti ve checkout aynı değerleri kullanır
+const (
+	taxRate        = 0.18
+	shippingFee    = 20.0
+	maxOrderAmount = 9999999999.99
+)
+
+var (
+	errLineAmountLimit  = errors.New("Tutar sınırı aşıldı (line)")
+	errTotalAmountLimit = errors.New("Tutar sınırı aşıldı (total)")
+)
+
+// pricedLine fiyatlandırılacak tek bir satır. UnitPrice ürünün güncel fiyatı,
+// ReferencePrice ise tasarruf hesabı için karşılaştırılan fiyattır (yoksa 0); sepette
+// sepete ekleme fiyatı ile üstü çizili fiyatın (original_price) büyüğüdür.
+type pricedLine struct {
+	ProductID      int
+	VariantID      *int
+	Quantity       int
+	UnitPrice      float64
+	ReferencePrice float64
+	LineTotal      float64
+}
+
+// savingsReferencePrice sepet satırının tasarruf hesabında karşılaştırılan fiyat: sepete ekleme
+// fiyatı ile üstü çizili fiyatın büyüğü. Böylece ürün sepete indirimdeyken eklendiyse de tasarruf
+// gösterilir. Kendi fiyatı olan varyantta ürünün indirimi geçerli olmadığından yalnızca ekleme
+// fiyatı kullanılır.
+func savingsReferencePrice(priceAtAdd float64, originalPrice *float64, variantHasOwnPrice bool) float64 {
+	if originalPrice == nil || variantHasOwnPrice {
+		return priceAtAdd
+	}
+	return math.Max(priceAtAdd, *originalPrice)
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// calculateTotals satırları 2 ondalıkla yuvarlayarak ara toplam, vergi, kargo ve
+// genel toplamı hesaplar. Satırların UnitPrice/LineTotal alanları yuvarlanmış
+// değerlerle güncellenir. GetCartItems özeti ve CreateOrder bu fonksiyonu paylaşır.
+func calculateTotals(lines []pricedLine) (models.CartSummary, error) {
+	var summary models.CartSummary
+
+	for i := range lines {
+		unit := roundMoney(lines[i].UnitPrice)
+		line := roundMoney(unit * float64(lines[i].Quantity))
+
+		// makul sınır kontrolü (ör: 9,999,999,999.99)
+		if line > maxOrderAmount {
+			return summary, errLineAmountLimit
+		}
+
+		lines[i].UnitPrice = unit
+		lines[i].LineTotal = line
+		summary.ItemCount += lines[i].Quantity
+		summary.Subtotal = roundMoney(summary.Subtotal + line)
+
+		if lines[i].ReferencePrice > unit {
+			summary.Savings = roundMoney(summary.Savings + (roundMoney(lines[i].ReferencePrice)-unit)*float64(lines[i].Quantity))
+		}
+	}
+
+	// Boş sepette kargo ücreti yansıtılmaz
+	if summary.ItemCount == 0 {
+		return summary, nil
+	}
+
+	summary.Tax = roundMoney(summary.Subtotal * taxRate)
+	summary.Shipping = shippingFee
+	summary.GrandTotal = roundMoney(summary.Subtotal + summary.Tax + summary.Shipping)
+
+	// Toplam için sınır kontrolü
+	if summary.GrandTotal > maxOrderAmount {
+		return summary, errTotalAmountLimit
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,131 @@
+// ========================================
+// internal/handlers/pricing_test.go - SEPET / SİPARİŞ TUTAR HESAPLAMA TESTLERİ
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/models"
+	"testing"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// testCartLine GetCartItems'ın özet satırını kurduğu girdiler
+type testCartLine struct {
+	priceAtAdd    float64
+	originalPrice *float64
+	ownPrice      bool
+	unitPrice     float64
+	quantity      int
+}
+
+func TestCalculateTotals(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []testCartLine
+		want  models.CartSummary
+	}{
+		{
+			name:  "boş sepette kargo yansıtılmaz",
+			lines: nil,
+			want:  models.CartSummary{},
+		},
+		{
+			name: "ekleme fiyatı üstü çizili fiyattan yüksek",
+			lines: []testCartLine{
+				{priceAtAdd: 120, originalPrice: floatPtr(100), unitPrice: 80, quantity: 2},
+			},
+			want: models.CartSummary{ItemCount: 2, Subtotal: 160, Savings: 80, Tax: 28.8, Shipping: 20, GrandTotal: 208.8},
+		},
+		{
+			name: "indirim sepete eklenmeden önce başladı",
+			lines: []testCartLine{
+				{priceAtAdd: 80, originalPrice: floatPtr(100), unitPrice: 80, quantity: 1},
+			},
+			want: models.CartSummary{ItemCount: 1, Subtotal: 80, Savings: 20, Tax: 14.4, Shipping: 20, GrandTotal: 114.4},
+		},
+		{
+			name: "indirimin süresi doldu",
+			lines: []testCartLine{
+				{priceAtAdd: 80, originalPrice: nil, unitPrice: 100, quantity: 1},
+			},
+			want: models.CartSummary{ItemCount: 1, Subtotal: 100, Savings: 0, Tax: 18, Shipping: 20, GrandTotal: 138},
+		},
+		{
+			name: "kendi fiyatı olan varyantta ürün indirimi sayılmaz",
+			lines: []testCartLine{
+				{priceAtAdd: 50, originalPrice: floatPtr(100), ownPrice: true, unitPrice: 50, quantity: 1},
+			},
+			want: models.CartSummary{ItemCount: 1, Subtotal: 50, Savings: 0, Tax: 9, Shipping: 20, GrandTotal: 79},
+		},
+		{
+			name: "birim fiyat ve toplamlar 2 ondalığa yuvarlanır",
+			lines: []testCartLine{
+				{priceAtAdd: 12.346, unitPrice: 10.004, quantity: 3},
+				{priceAtAdd: 9.99, unitPrice: 9.99, quantity: 3},
+			},
+			want: models.CartSummary{ItemCount: 6, Subtotal: 59.97, Savings: 7.05, Tax: 10.79, Shipping: 20, GrandTotal: 90.76},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([]pricedLine, 0, len(tt.lines))
+			for i, l := range tt.lines {
+				lines = append(lines, pricedLine{
+					ProductID:      i + 1,
+					Quantity:       l.quantity,
+					UnitPrice:      l.unitPrice,
+					ReferencePrice: savingsReferencePrice(l.priceAtAdd, l.originalPrice, l.ownPrice),
+				})
+			}
+
+			got, err := calculateTotals(lines)
+			if err != nil {
+				t.Fatalf("beklenmeyen hata: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("özet = %+v, beklenen %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateTotalsRoundsLines(t *testing.T) {
+	lines := []pricedLine{{ProductID: 1, Quantity: 3, UnitPrice: 10.004}}
+	if _, err := calculateTotals(lines); err != nil {
+		t.Fatalf("beklenmeyen hata: %v", err)
+	}
+	if lines[0].UnitPrice != 10 || lines[0].LineTotal != 30 {
+		t.Errorf("satır = %.4f x 3 = %.4f, beklenen 10.00 x 3 = 30.00", lines[0].UnitPrice, lines[0].LineTotal)
+	}
+}
+
+func TestCalculateTotalsLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []pricedLine
+		want  error
+	}{
+		{
+			name:  "satır tutarı sınırı",
+			lines: []pricedLine{{ProductID: 1, Quantity: 2, UnitPrice: maxOrderAmount}},
+			want:  errLineAmountLimit,
+		},
+		{
+			name:  "genel toplam sınırı",
+			lines: []pricedLine{{ProductID: 1, Quantity: 1, UnitPrice: maxOrderAmount - 1}},
+			want:  errTotalAmountLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := calculateTotals(tt.lines); err != tt.want {
+				t.Errorf("hata = %v, beklenen %v", err, tt.want)
+			}
+		})
+	}
+}
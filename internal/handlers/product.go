@@ -8,6 +8,7 @@ import (
 	"ecommerce-backend/internal/config"
 	"ecommerce-backend/internal/database"
 	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/storage"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -17,164 +18,208 @@ import (
 	"github.com/supabase-community/supabase-go"
 )
 
+// maxProductPageSize listeleme sayfası başına en fazla ürün
+const maxProductPageSize = 100
+
+// Mevcut stok ve stok durumu hesaplamaları; inventory tablosunun "i" alias'ı ile
+// LEFT JOIN edildiği tüm ürün sorgularında ortak kullanılır. Aktif varyantı olan ürünlerde
+// stok varyant envanterlerinin toplamıdır. Diğer kullanıcıların süresi dolmamış sepet
+// hold'ları mevcut stoktan düşülür; kullanıcısı bilinmeyen sorgularda (listeleme, feed)
+// tüm aktif hold'lar düşülür.
+var (
+	availableStockSQL = productAvailableStockSQL("NULL")
+	stockStatusSQL    = productStockStatusSQL("NULL")
+)
+
+// currentUserSQL sorgunun $2 parametresindeki (boş olabilen) kullanıcı id'si
+const currentUserSQL = "NULLIF($2, '')::uuid"
+
+// hasActiveVariantsSQL ürünün stokunun varyantlardan gelip gelmediği
+const hasActiveVariantsSQL = `EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.is_active = true)`
+
+// heldStockSQL match koşuluna uyan, excludeUser dışındaki kullanıcıların aktif hold toplamı.
+// excludeUser bir uuid SQL ifadesidir (ör: currentUserSQL veya "NULL").
+func heldStockSQL(match, excludeUser string) string {
+	return `COALESCE((SELECT SUM(h.quantity) FROM cart_holds h WHERE ` + match +
+		` AND h.expires_at > NOW() AND h.user_id IS DISTINCT FROM ` + excludeUser + `), 0)`
+}
+
+// inventoryAvailableSQL varyantsız ürünün inventory kaydından hold'lar düşülmüş stoku
+func inventoryAvailableSQL(excludeUser string) string {
+	return `(COALESCE(i.quantity - i.reserved_quantity, 0) - ` +
+		heldStockSQL("h.product_id = p.id AND h.variant_id IS NULL", excludeUser) + `)`
+}
+
+// variantStocksSQL ürünün aktif varyantlarının hold'lar düşülmüş stokları ve minimum seviyeleri;
+// "vs" alias'lı alt sorgu olarak kullanılır
+func variantStocksSQL(excludeUser string) string {
+	return `(SELECT COALESCE(vi.quantity - vi.reserved_quantity, 0) - ` + heldStockSQL("h.variant_id = pv.id", excludeUser) + ` AS available,
+		        COALESCE(vi.min_stock_level, 0) AS min_level
+		 FROM product_variants pv
+		 LEFT JOIN variant_inventory vi ON vi.variant_id = pv.id
+		 WHERE pv.product_id = p.id AND pv.is_active = true) vs`
+}
+
+// productAvailableStockSQL hold'lar düşülmüş mevcut ürün stoku; varyantlı ürünlerde
+// varyantların (eksiye düşenler sıfır sayılarak) toplamı
+func productAvailableStockSQL(excludeUser string) string {
+	return `CASE
+				WHEN ` + hasActiveVariantsSQL + `
+				THEN (SELECT COALESCE(SUM(GREATEST(vs.available, 0)), 0) FROM ` + variantStocksSQL(excludeUser) + `)
+				ELSE ` + inventoryAvailableSQL(excludeUser) + `
+			END`
+}
+
+// productStockStatusSQL productAvailableStockSQL ile aynı değerlerden hesaplanan stok durumu.
+// Varyantlı ürün, minimum seviyesinin üstünde stoku olan bir varyant varsa IN_STOCK sayılır.
+func productStockStatusSQL(excludeUser string) string {
+	available := inventoryAvailableSQL(excludeUser)
+	return `CASE
+				WHEN ` + hasActiveVariantsSQL + ` THEN (
+					SELECT CASE
+						WHEN COALESCE(SUM(GREATEST(vs.available, 0)), 0) <= 0 THEN 'OUT_OF_STOCK'
+						WHEN BOOL_OR(vs.available > vs.min_level) THEN 'IN_STOCK'
+						ELSE 'LOW_STOCK'
+					END
+					FROM ` + variantStocksSQL(excludeUser) + `
+				)
+				WHEN i.id IS NULL THEN 'OUT_OF_STOCK'
+				WHEN ` + available + ` <= 0 THEN 'OUT_OF_STOCK'
+				WHEN ` + available + ` <= i.min_stock_level THEN 'LOW_STOCK'
+				ELSE 'IN_STOCK'
+			END`
+}
+
 type ProductHandler struct {
 	cfg            *config.Config
 	supabaseClient *supabase.Client // Opsiyonel: complex queries için
+	storage        storage.Storage  // Ürün görselleri için
 }
 
-func NewProductHandler(cfg *config.Config) *ProductHandler {
+func NewProductHandler(cfg *config.Config, store storage.Storage) *ProductHandler {
 	client, _ := supabase.NewClient(cfg.SupabaseURL, cfg.SupabaseKey, nil)
 	return &ProductHandler{
 		cfg:            cfg,
 		supabaseClient: client,
+		storage:        store,
 	}
 }
 
 func (h *ProductHandler) GetProducts(c *gin.Context) {
+	h.listProducts(c, "")
+}
+
+// listProducts katalog ve yönetim listelemesinin ortak gövdesi; visibility
+// boşsa yalnızca satıştaki ürünler döner
+func (h *ProductHandler) listProducts(c *gin.Context, visibility string) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
-	search := c.Query("search")
-	category := c.Query("category")
-	stockFilter := c.Query("stock_filter")
-
-	offset := (page - 1) * limit
-
-	// DÜZELTME: COALESCE sorununu çözmek için query'yi basitleştir
-	query := `
-		SELECT 
-			p.id,
-			COALESCE(p.title, '') AS title,
-			COALESCE(p.description, '') AS description,
-			COALESCE(p.price, 0) AS price,
-			COALESCE(p.image, '') AS image,
-			COALESCE(p.category, '') AS category,
-			COALESCE(p.sku, '') AS sku,
-			COALESCE(p.rating, 0) AS rating,
-			COALESCE(p.rating_count, 0) AS rating_count,
-			p.is_active, p.created_at, p.updated_at,
-			CASE WHEN i.id IS NULL THEN 0 ELSE i.id END as inv_id, 
-			CASE WHEN i.quantity IS NULL THEN 0 ELSE i.quantity END as quantity, 
-			CASE WHEN i.reserved_quantity IS NULL THEN 0 ELSE i.reserved_quantity END as reserved_quantity, 
-			CASE WHEN i.min_stock_level IS NULL THEN 0 ELSE i.min_stock_level END as min_stock_level, 
-			CASE WHEN i.max_stock_level IS NULL THEN 0 ELSE i.max_stock_level END as max_stock_level, 
-			i.cost_price, 
-			CASE WHEN i.updated_at IS NULL THEN p.updated_at ELSE i.updated_at END as inv_updated_at,
-			CASE WHEN i.quantity IS NULL OR i.reserved_quantity IS NULL THEN 0 ELSE (i.quantity - i.reserved_quantity) END as available_stock,
-			CASE 
-				WHEN i.id IS NULL THEN 'OUT_OF_STOCK'
-				WHEN (i.quantity - i.reserved_quantity) <= 0 THEN 'OUT_OF_STOCK'
-				WHEN (i.quantity - i.reserved_quantity) <= i.min_stock_level THEN 'LOW_STOCK'
-				ELSE 'IN_STOCK'
-			END as stock_status
-		FROM products p
-		LEFT JOIN inventory i ON p.id = i.product_id
-		WHERE p.is_active = true
-	`
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 5
+	}
+	if limit > maxProductPageSize {
+		limit = maxProductPageSize
+	}
+	filters, err := productFiltersFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filters.Visibility = visibility
 
-	args := []interface{}{}
-	argCount := 0
-
-	// Filtreler ekle
-	if search != "" {
-		// Use two distinct placeholders to avoid any driver confusion
-		argCount++
-		placeholderTitle := "$" + strconv.Itoa(argCount)
-		args = append(args, "%"+search+"%")
-		argCount++
-		placeholderDesc := "$" + strconv.Itoa(argCount)
-		args = append(args, "%"+search+"%")
-		query += " AND (p.title ILIKE " + placeholderTitle + " OR COALESCE(p.description, '') ILIKE " + placeholderDesc + ")"
-	}
-
-	if category != "" {
-		argCount++
-		query += " AND p.category = $" + strconv.Itoa(argCount)
-		args = append(args, category)
-	}
-
-	if stockFilter != "" {
-		switch stockFilter {
-		case "IN_STOCK":
-			query += " AND i.id IS NOT NULL AND (i.quantity - i.reserved_quantity) > i.min_stock_level"
-		case "LOW_STOCK":
-			query += " AND i.id IS NOT NULL AND (i.quantity - i.reserved_quantity) <= i.min_stock_level AND (i.quantity - i.reserved_quantity) > 0"
-		case "OUT_OF_STOCK":
-			query += " AND (i.id IS NULL OR (i.quantity - i.reserved_quantity) <= 0)"
+	// EKLEME: Cursor (keyset) sayfalama; cursor verilirse page yok sayılır.
+	// Cursor sıralamayı ve fuzzy modunu taşır, farklı bir sort ile kullanılamaz.
+	var after *productCursor
+	if raw := c.Query("cursor"); raw != "" {
+		if after, err = decodeProductCursor(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
+		filters.Fuzzy = filters.Fuzzy || after.Fuzzy
 	}
 
-	query += " ORDER BY p.created_at DESC"
-	argCount++
-	limitPlaceholder := "$" + strconv.Itoa(argCount)
-	args = append(args, limit)
-	argCount++
-	offsetPlaceholder := "$" + strconv.Itoa(argCount)
-	args = append(args, offset)
-	query += " LIMIT " + limitPlaceholder + " OFFSET " + offsetPlaceholder
-
-	// Debug: args array'ini logla
-	fmt.Printf("GetProducts - args: %v, len: %d\n", args, len(args))
-	fmt.Printf("GetProducts - query: %s\n", query)
-
-	// DÜZELTME: limit ve offset her zaman var, bu yüzden args asla boş olamaz
-	rows, err := database.DB.Query(query, args...)
+	sort, err := parseProductSort(c.Query("sort"), filters.Search != "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if after != nil {
+		if c.Query("sort") != "" && after.Sort != sort {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor farklı bir sıralamaya ait"})
+			return
+		}
+		sort = after.Sort
+	}
 
+	pageRequest := productPage{Sort: sort, Limit: limit, Offset: (page - 1) * limit, After: after}
+	products, nextCursor, err := loadProductPage(filters, pageRequest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürünler alınamadı: " + err.Error()})
 		return
 	}
-	defer rows.Close()
 
-	var products []models.ProductWithStock
-	for rows.Next() {
-		var product models.ProductWithStock
-		var inventory models.Inventory
-		// DÜZELTME: Null değerler için proper handling
-		var invID, invQuantity, invReserved, invMin, invMax int
-		var invCost *float64
-		var invUpdatedAt time.Time
-
-		err := rows.Scan(
-			&product.ID, &product.Title, &product.Description, &product.Price,
-			&product.Image, &product.Category, &product.SKU, &product.Rating,
-			&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
-			&invID, &invQuantity, &invReserved, &invMin, &invMax, &invCost, &invUpdatedAt,
-			&product.AvailableStock, &product.StockStatus,
-		)
+	// EKLEME: Aramada hiç sonuç yoksa yazım hatalarına toleranslı benzerlik aramasına düş
+	// ve "bunu mu demek istediniz" önerisi ekle. Sonraki sayfalar next_cursor ile (veya fuzzy=true) alınır.
+	fuzzyFallback, suggestion := false, ""
+	if filters.Search != "" && !filters.Fuzzy && len(products) == 0 && pageRequest.Offset == 0 && after == nil {
+		fuzzy := filters
+		fuzzy.Fuzzy = true
+		fuzzyProducts, fuzzyCursor, err := loadProductPage(fuzzy, pageRequest)
 		if err != nil {
-			continue
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürünler alınamadı: " + err.Error()})
+			return
 		}
-
-		// Inventory bilgisi varsa ekle (id > 0 kontrolü ile)
-		if invID > 0 {
-			inventory.ID = invID
-			inventory.ProductID = product.ID
-			inventory.Quantity = invQuantity
-			inventory.ReservedQuantity = invReserved
-			inventory.MinStockLevel = invMin
-			inventory.MaxStockLevel = invMax
-			if invCost != nil {
-				inventory.CostPrice = *invCost
-			} else {
-				inventory.CostPrice = 0.0 // Default value if NULL
+		if len(fuzzyProducts) > 0 {
+			titles := make([]string, len(fuzzyProducts))
+			for i, product := range fuzzyProducts {
+				titles[i] = product.Title
 			}
-			inventory.UpdatedAt = invUpdatedAt
-			product.Inventory = &inventory
-		} else {
-			// Inventory yok ise nil
-			product.Inventory = nil
+			products, nextCursor = fuzzyProducts, fuzzyCursor
+			fuzzyFallback, suggestion = true, didYouMean(filters.Search, titles)
+			filters = fuzzy
 		}
+	}
 
-		products = append(products, product)
+	// DÜZELTME: total artık sayfa boyutu değil, filtrelere uyan toplam ürün sayısı
+	// (sayfa ile aynı sorgu üreticisinden)
+	total, err := countProducts(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün sayısı alınamadı: " + err.Error()})
+		return
 	}
 
-	// EKLEME: Total count da döndür (frontend'in ihtiyacı olabilir)
-	c.JSON(http.StatusOK, gin.H{
-		"products": products,
-		"page":     page,
-		"limit":    limit,
-		"total":    len(products), // Mevcut sayfa için
-	})
+	if products == nil {
+		products = []models.ProductWithStock{}
+	}
+	response := gin.H{
+		"products":    products,
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + limit - 1) / limit,
+		"sort":        sort,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
+	}
+	if fuzzyFallback {
+		response["fuzzy"] = true
+		response["did_you_mean"] = suggestion
+	}
+
+	// EKLEME: Filtre paneli sayımları (?facets=false ile kapatılabilir)
+	if c.Query("facets") != "false" {
+		facets, err := loadProductFacets(filters)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Filtre sayımları alınamadı: " + err.Error()})
+			return
+		}
+		response["facets"] = facets
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // EKLEME: Tekil ürün getirme endpoint'i
@@ -187,6 +232,14 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 	// DEBUGGING: Log ekle
 	fmt.Printf("GetProduct called for ID: %d\n", productID)
+	h.respondProduct(c, productID)
+}
+
+// respondProduct aktif ürünü stok, varyant, görsel ve özellikleriyle döner ve görüntülemeyi kaydeder;
+// GetProduct ve GetProductBySlug tarafından kullanılır
+func (h *ProductHandler) respondProduct(c *gin.Context, productID int) {
+	// Giriş yapmış kullanıcının kendi sepet hold'ları stoktan düşülmez
+	userID := c.GetString("userID")
 
 	// DÜZELTME: COALESCE sorununu çözmek için query'yi basitleştir
 	query := `
@@ -197,10 +250,14 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 			COALESCE(p.price, 0) AS price,
 			COALESCE(p.image, '') AS image,
 			COALESCE(p.category, '') AS category,
+			p.category_id,
 			COALESCE(p.sku, '') AS sku,
 			COALESCE(p.rating, 0) AS rating,
 			COALESCE(p.rating_count, 0) AS rating_count,
 			p.is_active, p.created_at, p.updated_at,
+			p.max_per_order, p.max_per_customer, p.purchase_limit_window_days,
+			p.compare_at_price, p.sale_price, p.sale_starts_at, p.sale_ends_at,
+			p.slug, ` + productPriceColumnsSQL + `,
 			CASE WHEN i.id IS NULL THEN 0 ELSE i.id END as inv_id, 
 			CASE WHEN i.quantity IS NULL THEN 0 ELSE i.quantity END as quantity, 
 			CASE WHEN i.reserved_quantity IS NULL THEN 0 ELSE i.reserved_quantity END as reserved_quantity, 
@@ -208,16 +265,11 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 			CASE WHEN i.max_stock_level IS NULL THEN 0 ELSE i.max_stock_level END as max_stock_level, 
 			i.cost_price, 
 			CASE WHEN i.updated_at IS NULL THEN p.updated_at ELSE i.updated_at END as inv_updated_at,
-			CASE WHEN i.quantity IS NULL OR i.reserved_quantity IS NULL THEN 0 ELSE (i.quantity - i.reserved_quantity) END as available_stock,
-			CASE 
-				WHEN i.id IS NULL THEN 'OUT_OF_STOCK'
-				WHEN (i.quantity - i.reserved_quantity) <= 0 THEN 'OUT_OF_STOCK'
-				WHEN (i.quantity - i.reserved_quantity) <= i.min_stock_level THEN 'LOW_STOCK'
-				ELSE 'IN_STOCK'
-			END as stock_status
+			` + productAvailableStockSQL(currentUserSQL) + ` as available_stock,
+			` + productStockStatusSQL(currentUserSQL) + ` as stock_status
 		FROM products p
 		LEFT JOIN inventory i ON p.id = i.product_id
-		WHERE p.id = $1 AND p.is_active = true
+		WHERE p.id = $1 AND p.is_active = true AND p.deleted_at IS NULL
 	`
 
 	var product models.ProductWithStock
@@ -227,11 +279,14 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	var invUpdatedAt time.Time
 
 	// DÜZELTME: QueryRowContext kullan ve context timeout ekle
-	row := database.DB.QueryRow(query, productID)
-	err = row.Scan(
+	row := database.DB.QueryRow(query, productID, userID)
+	err := row.Scan(
 		&product.ID, &product.Title, &product.Description, &product.Price,
-		&product.Image, &product.Category, &product.SKU, &product.Rating,
+		&product.Image, &product.Category, &product.CategoryID, &product.SKU, &product.Rating,
 		&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
+		&product.MaxPerOrder, &product.MaxPerCustomer, &product.PurchaseLimitWindowDays,
+		&product.CompareAtPrice, &product.SalePrice, &product.SaleStartsAt, &product.SaleEndsAt,
+		&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
 		&invID, &invQuantity, &invReserved, &invMin, &invMax, &invCost, &invUpdatedAt,
 		&product.AvailableStock, &product.StockStatus,
 	)
@@ -266,60 +321,46 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		product.Inventory = nil
 	}
 
+	// EKLEME: Seçenekler ve aktif varyantlar (varyant stokları kendi envanterinden)
+	product.Options, err = loadProductOptions(product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün seçenekleri alınamadı: " + err.Error()})
+		return
+	}
+	product.Variants, err = loadProductVariants(product.ID, product.EffectivePrice, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün varyantları alınamadı: " + err.Error()})
+		return
+	}
+	product.Images, err = loadProductImages(database.DB, product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün görselleri alınamadı: " + err.Error()})
+		return
+	}
+	product.Attributes, err = loadProductAttributes(database.DB, product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün özellikleri alınamadı: " + err.Error()})
+		return
+	}
+
+	// EKLEME: Görüntüleme kaydı; hata ürün yanıtını engellemez
+	if err := recordProductView(product.ID, productViewerKey(c), h.cfg.ProductViewDebounce); err != nil {
+		fmt.Printf("Product view could not be recorded: ID %d: %v\n", productID, err)
+	}
+
 	fmt.Printf("Product found successfully: ID %d, Title: %s\n", productID, product.Title)
 	c.JSON(http.StatusOK, gin.H{"product": product})
 }
 
 func (h *ProductHandler) GetProductsCount(c *gin.Context) {
-	search := c.Query("search")
-	category := c.Query("category")
-	stockFilter := c.Query("stock_filter")
-
-	// DÜZELTME: DISTINCT COUNT kullan duplicate'ları önlemek için
-	query := `
-		SELECT COUNT(DISTINCT p.id)
-		FROM products p
-		LEFT JOIN inventory i ON p.id = i.product_id
-		WHERE p.is_active = true
-	`
-
-	args := []interface{}{}
-	argCount := 0
-
-	// Filtreler ekle (GetProducts ile aynı)
-	if search != "" {
-		argCount++
-		placeholderTitle := "$" + strconv.Itoa(argCount)
-		args = append(args, "%"+search+"%")
-		argCount++
-		placeholderDesc := "$" + strconv.Itoa(argCount)
-		args = append(args, "%"+search+"%")
-		query += " AND (p.title ILIKE " + placeholderTitle + " OR COALESCE(p.description, '') ILIKE " + placeholderDesc + ")"
-	}
-
-	if category != "" {
-		argCount++
-		query += " AND p.category = $" + strconv.Itoa(argCount)
-		args = append(args, category)
-	}
-
-	if stockFilter != "" {
-		switch stockFilter {
-		case "IN_STOCK":
-			query += " AND i.id IS NOT NULL AND (i.quantity - i.reserved_quantity) > i.min_stock_level"
-		case "LOW_STOCK":
-			query += " AND i.id IS NOT NULL AND (i.quantity - i.reserved_quantity) <= i.min_stock_level AND (i.quantity - i.reserved_quantity) > 0"
-		case "OUT_OF_STOCK":
-			query += " AND (i.id IS NULL OR (i.quantity - i.reserved_quantity) <= 0)"
-		}
+	filters, err := productFiltersFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	var count int
-	var err error
-
-	// DÜZELTME: Parametreleri her zaman tutarlı şekilde geçir
-	err = database.DB.QueryRow(query, args...).Scan(&count)
-
+	// Filtreler GetProducts ile aynı sorgu üreticisinden gelir
+	count, err := countProducts(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün sayısı alınamadı: " + err.Error()})
 		return
@@ -329,11 +370,15 @@ func (h *ProductHandler) GetProductsCount(c *gin.Context) {
 }
 
 func (h *ProductHandler) GetCategories(c *gin.Context) {
+	// EKLEME: Kategori tablosundaki aktif kategoriler + henüz bağlanmamış eski kategori metinleri.
+	// Ağaç yapısı için /api/v1/categories kullanılmalı.
 	query := `
-		SELECT DISTINCT category 
-		FROM products 
-		WHERE is_active = true AND category IS NOT NULL AND category != ''
-		ORDER BY category
+		SELECT name FROM categories WHERE is_active = true
+		UNION
+		SELECT DISTINCT category
+		FROM products
+		WHERE is_active = true AND deleted_at IS NULL AND category_id IS NULL AND category IS NOT NULL AND category != ''
+		ORDER BY 1
 	`
 
 	rows, err := database.DB.Query(query)
@@ -365,7 +410,7 @@ func (h *ProductHandler) GetLowStockCount(c *gin.Context) {
 		SELECT COUNT(*)
 		FROM products p
 		INNER JOIN inventory i ON p.id = i.product_id
-		WHERE p.is_active = true 
+		WHERE p.is_active = true AND p.deleted_at IS NULL
 		AND i.min_stock_level > 0
 		AND (i.quantity - i.reserved_quantity) <= i.min_stock_level
 		AND (i.quantity - i.reserved_quantity) > 0
@@ -396,23 +441,28 @@ func (h *ProductHandler) CheckProductStock(c *gin.Context) {
 
 	// EKLEME: Ürün var mı kontrolü
 	var productExists bool
-	productCheckQuery := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_active = true)"
+	productCheckQuery := "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_active = true AND deleted_at IS NULL)"
 	err = database.DB.QueryRow(productCheckQuery, productID).Scan(&productExists)
 	if err != nil || !productExists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
 		return
 	}
 
+	// EKLEME: Sepetlerdeki aktif stok hold'ları da mevcut stoktan düşülür
 	query := `
 		SELECT COALESCE((quantity - reserved_quantity), 0) as available_stock,
 		       COALESCE(quantity, 0) as total_stock,
-		       COALESCE(reserved_quantity, 0) as reserved_stock
+		       COALESCE(reserved_quantity, 0) as reserved_stock,
+		       COALESCE((
+		           SELECT SUM(h.quantity) FROM cart_holds h
+		           WHERE h.product_id = inventory.product_id AND h.variant_id IS NULL AND h.expires_at > NOW()
+		       ), 0) as held_stock
 		FROM inventory 
 		WHERE product_id = $1
 	`
 
-	var availableStock, totalStock, reservedStock int
-	err = database.DB.QueryRow(query, productID).Scan(&availableStock, &totalStock, &reservedStock)
+	var availableStock, totalStock, reservedStock, heldStock int
+	err = database.DB.QueryRow(query, productID).Scan(&availableStock, &totalStock, &reservedStock, &heldStock)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Inventory kaydı yok, stok 0
@@ -428,12 +478,14 @@ func (h *ProductHandler) CheckProductStock(c *gin.Context) {
 		return
 	}
 
+	availableStock -= heldStock
 	sufficient := availableStock >= req.Quantity
 
 	c.JSON(http.StatusOK, gin.H{
 		"available_stock": availableStock,
 		"total_stock":     totalStock,
 		"reserved_stock":  reservedStock,
+		"held_stock":      heldStock,
 		"requested":       req.Quantity,
 		"sufficient":      sufficient,
 	})
@@ -443,16 +495,33 @@ func (h *ProductHandler) CheckProductStock(c *gin.Context) {
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req struct {
 		Title         string   `json:"title" binding:"required"`
+		Slug          string   `json:"slug"` // boşsa başlıktan üretilir
 		Description   string   `json:"description"`
 		Price         float64  `json:"price" binding:"required"`
 		Image         string   `json:"image"`
 		Category      string   `json:"category"`
+		CategoryID    *int     `json:"category_id"`
 		SKU           string   `json:"sku"`
 		IsActive      *bool    `json:"is_active"`
 		InitialStock  *int     `json:"initial_stock"`
 		MinStockLevel *int     `json:"min_stock_level"`
 		MaxStockLevel *int     `json:"max_stock_level"`
 		CostPrice     *float64 `json:"cost_price"`
+
+		MaxPerOrder             *int `json:"max_per_order"`
+		MaxPerCustomer          *int `json:"max_per_customer"`
+		PurchaseLimitWindowDays *int `json:"purchase_limit_window_days"`
+
+		// İndirimli fiyat yalnızca sale_starts_at / sale_ends_at aralığında geçerlidir (boş = sınırsız)
+		CompareAtPrice *float64   `json:"compare_at_price"`
+		SalePrice      *float64   `json:"sale_price"`
+		SaleStartsAt   *time.Time `json:"sale_starts_at"`
+		SaleEndsAt     *time.Time `json:"sale_ends_at"`
+		// Fiyat veya indirimli fiyat maliyetin altındaysa açık onay gerekir
+		AllowBelowCost bool `json:"allow_below_cost"`
+
+		// Özellik kodu -> değer; kategorinin zorunlu özellikleri verilmelidir
+		Attributes map[string]interface{} `json:"attributes"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -460,6 +529,12 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	salePrice := positivePriceOrNil(req.SalePrice)
+	if err := validateSalePrice(req.Price, salePrice, req.SaleStartsAt, req.SaleEndsAt); err != nil {
+		respondPriceError(c, err)
+		return
+	}
+
 	isActive := true
 	if req.IsActive != nil {
 		isActive = *req.IsActive
@@ -473,21 +548,52 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
+	// EKLEME: Kategori id'si veya adından kategori ağacındaki düğüm bulunur
+	categoryID, categoryName, err := resolveProductCategory(tx, req.CategoryID, req.Category)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	// EKLEME: Türkçe karakterleri dönüştürülmüş benzersiz slug
+	slug, err := resolveProductSlug(tx, req.Slug, req.Title, 0)
+	if err != nil {
+		respondProductSlugError(c, err, req.Slug)
+		return
+	}
+	if err := saveProductSlugRedirect(tx, 0, "", slug); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Slug yönlendirmesi güncellenemedi: " + err.Error()})
+		return
+	}
+
 	insertQuery := `
-        INSERT INTO products (title, description, price, image, category, sku, rating, rating_count, is_active, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, 0, 0, $7, NOW(), NOW())
-        RETURNING id, title, description, price, image, category, sku, rating, rating_count, is_active, created_at, updated_at
+        INSERT INTO products AS p (title, description, price, image, category, category_id, sku, rating, rating_count, is_active,
+                              max_per_order, max_per_customer, purchase_limit_window_days,
+                              compare_at_price, sale_price, sale_starts_at, sale_ends_at, slug, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, 0, 0, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW(), NOW())
+        RETURNING id, title, description, price, image, category, category_id, sku, rating, rating_count, is_active, created_at, updated_at,
+                  max_per_order, max_per_customer, purchase_limit_window_days,
+                  compare_at_price, sale_price, sale_starts_at, sale_ends_at, p.slug, ` + productPriceColumnsSQL + `
     `
 
 	var product models.Product
 	err = tx.QueryRow(insertQuery,
-		req.Title, req.Description, req.Price, req.Image, req.Category, req.SKU, isActive,
+		req.Title, req.Description, req.Price, req.Image, categoryName, categoryID, req.SKU, isActive,
+		positiveOrNil(req.MaxPerOrder), positiveOrNil(req.MaxPerCustomer), positiveOrNil(req.PurchaseLimitWindowDays),
+		positivePriceOrNil(req.CompareAtPrice), salePrice, req.SaleStartsAt, req.SaleEndsAt, slug,
 	).Scan(
 		&product.ID, &product.Title, &product.Description, &product.Price, &product.Image,
-		&product.Category, &product.SKU, &product.Rating, &product.RatingCount,
+		&product.Category, &product.CategoryID, &product.SKU, &product.Rating, &product.RatingCount,
 		&product.IsActive, &product.CreatedAt, &product.UpdatedAt,
+		&product.MaxPerOrder, &product.MaxPerCustomer, &product.PurchaseLimitWindowDays,
+		&product.CompareAtPrice, &product.SalePrice, &product.SaleStartsAt, &product.SaleEndsAt,
+		&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			respondProductSlugError(c, err, slug)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün oluşturulamadı: " + err.Error()})
 		return
 	}
@@ -526,12 +632,34 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	// EKLEME: Maliyetin altındaki fiyat yalnızca allow_below_cost ile kabul edilir; fiyat geçmişe yazılır
+	belowCost, err := guardPriceAboveCost(tx, product.ID, req.AllowBelowCost)
+	if err != nil {
+		respondPriceError(c, err)
+		return
+	}
+	if err := recordPriceChange(tx, product.ID, priceSourceCreate, c.GetString("userID"), belowCost); err != nil {
+		respondPriceError(c, err)
+		return
+	}
+
+	// EKLEME: Yapılandırılmış özellik değerleri kategori tanımlarına göre doğrulanır
+	if err := saveProductAttributes(tx, product.ID, categoryID, req.Attributes, true); err != nil {
+		respondAttributeError(c, err)
+		return
+	}
+	attributes, err := loadProductAttributes(tx, product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün özellikleri alınamadı: " + err.Error()})
+		return
+	}
+
 	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"product": product})
+	c.JSON(http.StatusCreated, gin.H{"product": product, "attributes": attributes})
 }
 
 // UpdateProduct updates an existing product
@@ -550,6 +678,29 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		Category    *string  `json:"category"`
 		SKU         *string  `json:"sku"`
 		IsActive    *bool    `json:"is_active"`
+
+		// Değişen slug'ın eskisi yeni slug'a yönlendirilir; boş gönderilirse başlıktan yeniden üretilir
+		Slug *string `json:"slug"`
+
+		// 0 gönderilirse ürünün kategori bağlantısı kaldırılır
+		CategoryID *int `json:"category_id"`
+
+		// 0 gönderilirse limit kaldırılır
+		MaxPerOrder             *int `json:"max_per_order"`
+		MaxPerCustomer          *int `json:"max_per_customer"`
+		PurchaseLimitWindowDays *int `json:"purchase_limit_window_days"`
+
+		// 0 gönderilirse karşılaştırma fiyatı / indirim kaldırılır. sale_price gönderildiğinde
+		// indirim aralığı istekteki sale_starts_at / sale_ends_at ile değiştirilir (boş = sınırsız).
+		CompareAtPrice *float64   `json:"compare_at_price"`
+		SalePrice      *float64   `json:"sale_price"`
+		SaleStartsAt   *time.Time `json:"sale_starts_at"`
+		SaleEndsAt     *time.Time `json:"sale_ends_at"`
+		// Fiyat veya indirimli fiyat maliyetin altına düşüyorsa açık onay gerekir
+		AllowBelowCost bool `json:"allow_below_cost"`
+
+		// Özellik kodu -> değer; null değer özelliği siler, verilmeyenler değişmez
+		Attributes map[string]interface{} `json:"attributes"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -557,15 +708,29 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	// DÜZELTME: Ürün ve özellik değerleri birlikte güncellendiği için transaction kullanılır
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
 	// Fetch existing product
 	var existing models.Product
-	err = database.DB.QueryRow(
-		"SELECT id, title, description, price, image, category, sku, rating, rating_count, is_active, created_at, updated_at FROM products WHERE id = $1",
+	err = tx.QueryRow(
+		`SELECT id, title, description, price, image, COALESCE(category, ''), category_id, sku, rating, rating_count, is_active, created_at, updated_at,
+		        max_per_order, max_per_customer, purchase_limit_window_days,
+		        compare_at_price, sale_price, sale_starts_at, sale_ends_at, slug
+		 FROM products WHERE id = $1 FOR UPDATE`,
 		productID,
 	).Scan(
 		&existing.ID, &existing.Title, &existing.Description, &existing.Price, &existing.Image,
-		&existing.Category, &existing.SKU, &existing.Rating, &existing.RatingCount,
+		&existing.Category, &existing.CategoryID, &existing.SKU, &existing.Rating, &existing.RatingCount,
 		&existing.IsActive, &existing.CreatedAt, &existing.UpdatedAt,
+		&existing.MaxPerOrder, &existing.MaxPerCustomer, &existing.PurchaseLimitWindowDays,
+		&existing.CompareAtPrice, &existing.SalePrice, &existing.SaleStartsAt, &existing.SaleEndsAt,
+		&existing.Slug,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -586,11 +751,35 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	if req.Price != nil {
 		existing.Price = *req.Price
 	}
+	// EKLEME: Başlık değişince slug değişmez (linkler bozulmasın); yalnızca açıkça istenirse değişir
+	if req.Slug != nil {
+		slug, err := resolveProductSlug(tx, *req.Slug, existing.Title, productID)
+		if err != nil {
+			respondProductSlugError(c, err, *req.Slug)
+			return
+		}
+		if err := saveProductSlugRedirect(tx, productID, existing.Slug, slug); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Slug yönlendirmesi kaydedilemedi: " + err.Error()})
+			return
+		}
+		existing.Slug = slug
+	}
 	if req.Image != nil {
 		existing.Image = *req.Image
 	}
-	if req.Category != nil {
-		existing.Category = *req.Category
+	categoryChanged := req.CategoryID != nil || req.Category != nil
+	if req.CategoryID != nil && *req.CategoryID <= 0 {
+		existing.CategoryID, existing.Category = nil, ""
+	} else if categoryChanged {
+		name := ""
+		if req.Category != nil {
+			name = *req.Category
+		}
+		existing.CategoryID, existing.Category, err = resolveProductCategory(tx, req.CategoryID, name)
+		if err != nil {
+			respondCategoryError(c, err)
+			return
+		}
 	}
 	if req.SKU != nil {
 		existing.SKU = *req.SKU
@@ -598,30 +787,111 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	if req.IsActive != nil {
 		existing.IsActive = *req.IsActive
 	}
+	if req.MaxPerOrder != nil {
+		existing.MaxPerOrder = positiveOrNil(req.MaxPerOrder)
+	}
+	if req.MaxPerCustomer != nil {
+		existing.MaxPerCustomer = positiveOrNil(req.MaxPerCustomer)
+	}
+	if req.PurchaseLimitWindowDays != nil {
+		existing.PurchaseLimitWindowDays = positiveOrNil(req.PurchaseLimitWindowDays)
+	}
+
+	// EKLEME: İndirim ve karşılaştırma fiyatları
+	priceChanged := req.Price != nil || req.CompareAtPrice != nil || req.SalePrice != nil ||
+		req.SaleStartsAt != nil || req.SaleEndsAt != nil
+	if req.CompareAtPrice != nil {
+		existing.CompareAtPrice = positivePriceOrNil(req.CompareAtPrice)
+	}
+	if req.SalePrice != nil {
+		existing.SalePrice = positivePriceOrNil(req.SalePrice)
+		existing.SaleStartsAt, existing.SaleEndsAt = req.SaleStartsAt, req.SaleEndsAt
+	} else {
+		if req.SaleStartsAt != nil {
+			existing.SaleStartsAt = req.SaleStartsAt
+		}
+		if req.SaleEndsAt != nil {
+			existing.SaleEndsAt = req.SaleEndsAt
+		}
+	}
+	if existing.SalePrice == nil {
+		existing.SaleStartsAt, existing.SaleEndsAt = nil, nil
+	}
+	if priceChanged {
+		if err := validateSalePrice(existing.Price, existing.SalePrice, existing.SaleStartsAt, existing.SaleEndsAt); err != nil {
+			respondPriceError(c, err)
+			return
+		}
+	}
 
 	updateQuery := `
-        UPDATE products
+        UPDATE products AS p
         SET title = $1, description = $2, price = $3, image = $4, category = $5,
-            sku = $6, is_active = $7, updated_at = NOW()
-        WHERE id = $8
-        RETURNING id, title, description, price, image, category, sku, rating, rating_count, is_active, created_at, updated_at
+            sku = $6, is_active = $7, max_per_order = $8, max_per_customer = $9,
+            purchase_limit_window_days = $10, category_id = $12,
+            compare_at_price = $13, sale_price = $14, sale_starts_at = $15, sale_ends_at = $16, slug = $17, updated_at = NOW()
+        WHERE id = $11
+        RETURNING id, title, description, price, image, category, category_id, sku, rating, rating_count, is_active, created_at, updated_at,
+                  max_per_order, max_per_customer, purchase_limit_window_days,
+                  compare_at_price, sale_price, sale_starts_at, sale_ends_at, p.slug, ` + productPriceColumnsSQL + `
     `
 
 	var updated models.Product
-	err = database.DB.QueryRow(updateQuery,
+	err = tx.QueryRow(updateQuery,
 		existing.Title, existing.Description, existing.Price, existing.Image,
-		existing.Category, existing.SKU, existing.IsActive, productID,
+		existing.Category, existing.SKU, existing.IsActive,
+		existing.MaxPerOrder, existing.MaxPerCustomer, existing.PurchaseLimitWindowDays, productID, existing.CategoryID,
+		existing.CompareAtPrice, existing.SalePrice, existing.SaleStartsAt, existing.SaleEndsAt, existing.Slug,
 	).Scan(
 		&updated.ID, &updated.Title, &updated.Description, &updated.Price, &updated.Image,
-		&updated.Category, &updated.SKU, &updated.Rating, &updated.RatingCount,
+		&updated.Category, &updated.CategoryID, &updated.SKU, &updated.Rating, &updated.RatingCount,
 		&updated.IsActive, &updated.CreatedAt, &updated.UpdatedAt,
+		&updated.MaxPerOrder, &updated.MaxPerCustomer, &updated.PurchaseLimitWindowDays,
+		&updated.CompareAtPrice, &updated.SalePrice, &updated.SaleStartsAt, &updated.SaleEndsAt,
+		&updated.Slug, &updated.EffectivePrice, &updated.OriginalPrice,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			respondProductSlugError(c, err, existing.Slug)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün güncellenemedi: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"product": updated})
+	// EKLEME: Fiyat değiştiyse maliyet kontrolü ve fiyat geçmişi
+	if priceChanged {
+		belowCost, err := guardPriceAboveCost(tx, productID, req.AllowBelowCost)
+		if err != nil {
+			respondPriceError(c, err)
+			return
+		}
+		if err := recordPriceChange(tx, productID, priceSourceUpdate, c.GetString("userID"), belowCost); err != nil {
+			respondPriceError(c, err)
+			return
+		}
+	}
+
+	// EKLEME: Kategori değişince yeni kategoride tanımlı olmayan özellik değerleri kaldırılır
+	// ve zorunlu özellikler yeniden kontrol edilir
+	if req.Attributes != nil || categoryChanged {
+		if err := saveProductAttributes(tx, productID, updated.CategoryID, req.Attributes, true); err != nil {
+			respondAttributeError(c, err)
+			return
+		}
+	}
+	attributes, err := loadProductAttributes(tx, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün özellikleri alınamadı: " + err.Error()})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product": updated, "attributes": attributes})
 }
 
 // ALTERNATIF: Daha basit Supabase versiyonu (eğer Range hala sorun yaparsa)
@@ -643,3 +913,11 @@ func (h *ProductHandler) GetProductsSimpleSupabase(c *gin.Context) {
 		"note":     "Basit Supabase query (Range olmadan)",
 	})
 }
+
+// positiveOrNil 0 veya negatif limit değerlerini "limit yok" (NULL) olarak yorumlar
+func positiveOrNil(v *int) *int {
+	if v == nil || *v <= 0 {
+		return nil
+	}
+	return v
+}
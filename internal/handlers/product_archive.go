@@ -0,0 +1,189 @@
+// ========================================
+// internal/handlers/product_archive.go - ÜRÜN ARŞİVLEME, GERİ YÜKLEME VE KALICI SİLME
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/database"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// GetAdminProducts yönetim listesi; ?status=all|active|inactive|archived (varsayılan all).
+// Diğer tüm filtre, sıralama ve sayfalama parametreleri GetProducts ile aynıdır.
+func (h *ProductHandler) GetAdminProducts(c *gin.Context) {
+	status := c.DefaultQuery("status", productVisibilityAll)
+	switch status {
+	case productVisibilityAll, productVisibilityActive, productVisibilityInactive, productVisibilityArchived:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz status (all, active, inactive, archived)"})
+		return
+	}
+	h.listProducts(c, status)
+}
+
+// DeleteProduct ürünü arşivler (soft delete). Arşivlenen ürün katalogda görünmez ve
+// satın alınamaz; sepet ve sipariş geçmişindeki kayıtlar korunur.
+// ?permanent=true ile kalıcı silme yapılır (bkz. hardDeleteProduct).
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	if c.Query("permanent") == "true" {
+		h.hardDeleteProduct(c, productID)
+		return
+	}
+
+	var deletedAt time.Time
+	err = database.DB.QueryRow(`
+		UPDATE products SET deleted_at = COALESCE(deleted_at, NOW()), updated_at = NOW()
+		WHERE id = $1
+		RETURNING deleted_at
+	`, productID).Scan(&deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün arşivlenemedi: " + err.Error()})
+		return
+	}
+
+	// Arşivlenen ürün için sepetlerde tutulan stok artık ayrılmaz
+	if _, err := database.DB.Exec("DELETE FROM cart_holds WHERE product_id = $1", productID); err != nil {
+		fmt.Printf("Cart holds cleanup failed for archived product %d: %v\n", productID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ürün arşivlendi", "product_id": productID, "deleted_at": deletedAt})
+}
+
+// RestoreProduct arşivlenmiş ürünü geri yükler
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	var wasArchived bool
+	err = database.DB.QueryRow(`
+		UPDATE products p SET deleted_at = NULL, updated_at = NOW()
+		FROM (SELECT id, deleted_at IS NOT NULL AS was_archived FROM products WHERE id = $1 FOR UPDATE) old
+		WHERE p.id = old.id
+		RETURNING old.was_archived
+	`, productID).Scan(&wasArchived)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün geri yüklenemedi: " + err.Error()})
+		return
+	}
+	if !wasArchived {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ürün arşivde değil"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ürün geri yüklendi", "product_id": productID})
+}
+
+// hardDeleteProduct ürünü ve ona bağlı sepet, istek listesi, stok, görsel ve varyant
+// kayıtlarını kalıcı olarak siler. Siparişlerde geçen ürünler sipariş geçmişi bozulmasın
+// diye silinemez; bunlar yalnızca arşivlenebilir.
+func (h *ProductHandler) hardDeleteProduct(c *gin.Context, productID int) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Ürün satırı kilitlenir; kontrol ile silme arasında yeni sipariş oluşamaz
+	// (checkout ürün fiyatını aynı satırdan okur)
+	if err = lockProductGallery(tx, productID); err != nil {
+		respondGalleryLockError(c, err)
+		return
+	}
+
+	var orderCount int
+	if err = tx.QueryRow("SELECT COUNT(*) FROM order_items WHERE product_id = $1", productID).Scan(&orderCount); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sipariş kontrolü yapılamadı: " + err.Error()})
+		return
+	}
+	if orderCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       "Siparişlerde geçen ürün kalıcı olarak silinemez, arşivleyebilirsiniz",
+			"order_items": orderCount,
+		})
+		return
+	}
+
+	// Storage temizliği commit sonrası yapılır; önce nesne yollarını topla
+	rows, err := tx.Query("SELECT object_path, variant_paths FROM product_images WHERE product_id = $1", productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün görselleri alınamadı: " + err.Error()})
+		return
+	}
+	var paths []string
+	for rows.Next() {
+		var objectPath string
+		var variantPaths []string
+		if err := rows.Scan(&objectPath, pq.Array(&variantPaths)); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün görselleri alınamadı: " + err.Error()})
+			return
+		}
+		if len(variantPaths) > 0 {
+			paths = append(paths, variantPaths...)
+		} else {
+			paths = append(paths, objectPath)
+		}
+	}
+	rows.Close()
+
+	// Varyant, seçenek, görsel, stok tutma ve istek listesi kayıtları ON DELETE CASCADE
+	// ile silinir; cascade tanımı olmayan tablolar açıkça temizlenir
+	for _, stmt := range []string{
+		"DELETE FROM cart_items WHERE product_id = $1",
+		"DELETE FROM inventory WHERE product_id = $1",
+		"DELETE FROM products WHERE id = $1",
+	} {
+		if _, err = tx.Exec(stmt, productID); err != nil {
+			if isForeignKeyViolation(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Ürün başka kayıtlarda kullanıldığı için silinemedi, arşivleyebilirsiniz"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün silinemedi: " + err.Error()})
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	response := gin.H{"message": "Ürün kalıcı olarak silindi", "product_id": productID}
+	if err := deleteObjects(c.Request.Context(), h.storage, h.cfg.ProductImagesBucket, paths); err != nil {
+		fmt.Printf("Product storage cleanup failed for %d: %v\n", productID, err)
+		response["storage_cleanup_failed"] = true
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// isForeignKeyViolation Postgres foreign_key_violation (23503) hatasını tanır
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
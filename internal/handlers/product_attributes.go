@@ -0,0 +1,334 @@
+// ========================================
+// internal/handlers/product_attributes.go - ÜRÜN ÖZELLİK DEĞERLERİ (DOĞRULAMA, KAYIT, OKUMA)
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/models"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// Özellik tipleri
+const (
+	attributeTypeText    = "text"
+	attributeTypeNumber  = "number"
+	attributeTypeEnum    = "enum"
+	attributeTypeBoolean = "boolean"
+)
+
+// maxAttributeTextLength text tipindeki değerin en fazla karakter sayısı
+const maxAttributeTextLength = 500
+
+// attributeError özellik değerinin geçersiz olduğunu belirtir (400)
+type attributeError struct {
+	Code    string
+	Message string
+}
+
+func (e *attributeError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return e.Code + ": " + e.Message
+}
+
+// respondAttributeError doğrulama hatalarını 400, diğerlerini 500 olarak döner
+func respondAttributeError(c *gin.Context, err error) {
+	if attrErr, ok := err.(*attributeError); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": attrErr.Message, "attribute": attrErr.Code})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün özellikleri kaydedilemedi: " + err.Error()})
+}
+
+// loadCategoryAttributes kategoride ve üst kategorilerinde tanımlı özellikleri döner.
+// Aynı özellik birden çok seviyede atanmışsa en yakın kategorinin ayarı (zorunluluk, sıra) geçerlidir.
+func loadCategoryAttributes(q queryer, categoryID int) ([]models.CategoryAttribute, error) {
+	rows, err := q.Query(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id, c.parent_id, a.depth + 1
+			FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			WHERE a.depth < 32
+		)
+		SELECT DISTINCT ON (ad.id)
+		       ad.id, ad.code, ad.name, ad.type, ad.unit, ad.options, ad.is_filterable, ad.sort_order,
+		       ad.created_at, ad.updated_at, ca.is_required, ca.sort_order, ca.category_id
+		FROM ancestors a
+		JOIN category_attributes ca ON ca.category_id = a.id
+		JOIN attribute_definitions ad ON ad.id = ca.attribute_id
+		ORDER BY ad.id, a.depth
+	`, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []models.CategoryAttribute{}
+	for rows.Next() {
+		var attribute models.CategoryAttribute
+		var assignedTo int
+		if err := rows.Scan(
+			&attribute.ID, &attribute.Code, &attribute.Name, &attribute.Type, &attribute.Unit,
+			pq.Array(&attribute.Options), &attribute.IsFilterable, &attribute.SortOrder,
+			&attribute.CreatedAt, &attribute.UpdatedAt, &attribute.IsRequired, &attribute.Position, &assignedTo,
+		); err != nil {
+			return nil, err
+		}
+		if assignedTo != categoryID {
+			attribute.InheritedFrom = &assignedTo
+		}
+		attributes = append(attributes, attribute)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(attributes, func(i, j int) bool {
+		if attributes[i].Position != attributes[j].Position {
+			return attributes[i].Position < attributes[j].Position
+		}
+		if attributes[i].SortOrder != attributes[j].SortOrder {
+			return attributes[i].SortOrder < attributes[j].SortOrder
+		}
+		return attributes[i].Name < attributes[j].Name
+	})
+	return attributes, nil
+}
+
+// normalizeAttributeValue istekteki değeri tipine göre doğrular ve kanonik metin (value_text)
+// ile sayısal değere (yalnızca number) çevirir
+func normalizeAttributeValue(def models.AttributeDefinition, raw interface{}) (string, *float64, error) {
+	invalid := func(message string) (string, *float64, error) {
+		return "", nil, &attributeError{Code: def.Code, Message: def.Name + " " + message}
+	}
+
+	switch def.Type {
+	case attributeTypeNumber:
+		var number float64
+		switch v := raw.(type) {
+		case float64:
+			number = v
+		case string:
+			// JSON sayıları float64 gelir; metin olarak gönderilen "12,5" gibi değerler de kabul edilir
+			parsed, err := parseDecimal(strings.TrimSpace(v))
+			if err != nil {
+				return invalid("sayı olmalı")
+			}
+			number = *parsed
+		default:
+			return invalid("sayı olmalı")
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), &number, nil
+
+	case attributeTypeBoolean:
+		switch v := raw.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "evet", "1":
+				return "true", nil, nil
+			case "false", "hayır", "hayir", "0":
+				return "false", nil, nil
+			}
+		}
+		return invalid("true veya false olmalı")
+
+	case attributeTypeEnum:
+		value, ok := raw.(string)
+		if !ok {
+			return invalid("metin olmalı")
+		}
+		for _, option := range def.Options {
+			if slugify(option) == slugify(value) {
+				return option, nil, nil
+			}
+		}
+		return invalid("için geçersiz değer (izin verilenler: " + strings.Join(def.Options, ", ") + ")")
+
+	default:
+		value, ok := raw.(string)
+		if !ok {
+			return invalid("metin olmalı")
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return invalid("boş olamaz")
+		}
+		if len([]rune(value)) > maxAttributeTextLength {
+			return invalid(fmt.Sprintf("en fazla %d karakter olabilir", maxAttributeTextLength))
+		}
+		return value, nil, nil
+	}
+}
+
+// saveProductAttributes ürünün özellik değerlerini yazar. values kod -> değer eşlemesidir; nil değer
+// özelliği siler, verilmeyen özellikler değişmez. Ürünün kategorisine (veya üst kategorilerine)
+// atanmamış özelliklerin değerleri kaldırılır; böylece kategori değişince eski değerler kalmaz.
+// checkRequired true ise kategorinin zorunlu özelliklerinin hepsinin değeri olmalıdır.
+func saveProductAttributes(tx *sql.Tx, productID int, categoryID *int, values map[string]interface{}, checkRequired bool) error {
+	var allowed []models.CategoryAttribute
+	if categoryID != nil {
+		var err error
+		if allowed, err = loadCategoryAttributes(tx, *categoryID); err != nil {
+			return err
+		}
+	}
+	byCode := make(map[string]models.CategoryAttribute, len(allowed))
+	allowedIDs := make([]int64, 0, len(allowed))
+	for _, attribute := range allowed {
+		byCode[attribute.Code] = attribute
+		allowedIDs = append(allowedIDs, int64(attribute.ID))
+	}
+
+	codes := make([]string, 0, len(values))
+	for code := range values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		attribute, ok := byCode[code]
+		if !ok {
+			return &attributeError{Code: code, Message: "Özellik ürünün kategorisinde tanımlı değil: " + code}
+		}
+		if values[code] == nil {
+			if _, err := tx.Exec("DELETE FROM product_attribute_values WHERE product_id = $1 AND attribute_id = $2", productID, attribute.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		text, number, err := normalizeAttributeValue(attribute.AttributeDefinition, values[code])
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO product_attribute_values (product_id, attribute_id, value_text, value_number)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (product_id, attribute_id) DO UPDATE SET
+			  value_text = EXCLUDED.value_text,
+			  value_number = EXCLUDED.value_number
+		`, productID, attribute.ID, text, number)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(
+		"DELETE FROM product_attribute_values WHERE product_id = $1 AND NOT (attribute_id = ANY($2))",
+		productID, pq.Array(allowedIDs),
+	); err != nil {
+		return err
+	}
+
+	if !checkRequired {
+		return nil
+	}
+	rows, err := tx.Query("SELECT attribute_id FROM product_attribute_values WHERE product_id = $1", productID)
+	if err != nil {
+		return err
+	}
+	present := map[int]bool{}
+	for rows.Next() {
+		var attributeID int
+		if err := rows.Scan(&attributeID); err != nil {
+			rows.Close()
+			return err
+		}
+		present[attributeID] = true
+	}
+	rows.Close()
+	var missing []string
+	for _, attribute := range allowed {
+		if attribute.IsRequired && !present[attribute.ID] {
+			missing = append(missing, attribute.Code)
+		}
+	}
+	if len(missing) > 0 {
+		return &attributeError{Code: missing[0], Message: "Zorunlu özellikler eksik: " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
+
+// loadProductAttributes ürünün özellik değerlerini tipli değer ve gösterim metniyle döner
+func loadProductAttributes(q queryer, productID int) ([]models.ProductAttributeValue, error) {
+	rows, err := q.Query(`
+		SELECT ad.id, ad.code, ad.name, ad.type, ad.unit, pav.value_text, pav.value_number::float8
+		FROM product_attribute_values pav
+		JOIN attribute_definitions ad ON ad.id = pav.attribute_id
+		WHERE pav.product_id = $1
+		ORDER BY ad.sort_order, ad.name
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []models.ProductAttributeValue{}
+	for rows.Next() {
+		var attribute models.ProductAttributeValue
+		var text string
+		var number *float64
+		if err := rows.Scan(&attribute.AttributeID, &attribute.Code, &attribute.Name, &attribute.Type, &attribute.Unit, &text, &number); err != nil {
+			return nil, err
+		}
+
+		attribute.Value, attribute.Display = text, text
+		switch attribute.Type {
+		case attributeTypeNumber:
+			if number != nil {
+				attribute.Value = *number
+			}
+		case attributeTypeBoolean:
+			attribute.Value = text == "true"
+			attribute.Display = "Hayır"
+			if text == "true" {
+				attribute.Display = "Evet"
+			}
+		}
+		if attribute.Unit != "" && attribute.Type != attributeTypeBoolean {
+			attribute.Display += " " + attribute.Unit
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes, rows.Err()
+}
+
+// attributeRange number tipindeki özellik filtresinin aralığı ("1..5", "..5", "1..")
+type attributeRange struct {
+	Min *float64
+	Max *float64
+}
+
+// splitAttributeFilterValues filtre değerlerini tam eşleşme değerleri, sayılar ve aralıklara ayırır
+func splitAttributeFilterValues(values []string) (exact []string, numbers []float64, ranges []attributeRange) {
+	for _, value := range values {
+		if low, high, ok := strings.Cut(value, ".."); ok {
+			var r attributeRange
+			if n, err := parseDecimal(strings.TrimSpace(low)); err == nil {
+				r.Min = n
+			}
+			if n, err := parseDecimal(strings.TrimSpace(high)); err == nil {
+				r.Max = n
+			}
+			if r.Min != nil || r.Max != nil {
+				ranges = append(ranges, r)
+			}
+			continue
+		}
+		exact = append(exact, value)
+		if n, err := parseDecimal(value); err == nil {
+			numbers = append(numbers, *n)
+		}
+	}
+	return exact, numbers, ranges
+}
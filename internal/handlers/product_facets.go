@@ -0,0 +1,346 @@
+// ========================================
+// internal/handlers/product_facets.go - LİSTELEME FİLTRE PANELİ SAYIMLARI (FACETS)
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"sort"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// priceFacetBounds fiyat aralığı kovalarının sınırları: [0,100), [100,250) ... [5000, ∞)
+var priceFacetBounds = []float64{100, 250, 500, 1000, 2500, 5000}
+
+// stockStatuses stok durumu facet'inde her zaman dönen değerler (sayı 0 olsa bile)
+var stockStatuses = []string{"IN_STOCK", "LOW_STOCK", "OUT_OF_STOCK"}
+
+// loadProductFacets mevcut filtre setine göre kategori, fiyat, stok durumu ve seçenek
+// sayımlarını hesaplar. Her boyut kendi filtresi hariç tutularak sayılır; böylece
+// örneğin bir kategori seçiliyken diğer kategorilerin sayıları da görünür.
+func loadProductFacets(f productFilters) (*models.ProductFacets, error) {
+	facets := &models.ProductFacets{}
+	var err error
+
+	withoutCategory := f
+	withoutCategory.CategoryID, withoutCategory.Category, withoutCategory.Categories = 0, "", nil
+	if facets.Categories, err = loadCategoryFacets(withoutCategory); err != nil {
+		return nil, err
+	}
+
+	withoutPrice := f
+	withoutPrice.MinPrice, withoutPrice.MaxPrice = nil, nil
+	if facets.PriceBuckets, facets.PriceRange, err = loadPriceFacets(withoutPrice); err != nil {
+		return nil, err
+	}
+
+	withoutStock := f
+	withoutStock.StockFilter = ""
+	if facets.StockStatus, err = loadStockFacets(withoutStock); err != nil {
+		return nil, err
+	}
+
+	if facets.Attributes, err = loadAttributeFacets(f); err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// loadCategoryFacets ürünleri doğrudan bağlı oldukları kategoriye göre sayar, sonra
+// sayıları üst kategorilere ekler (kategori filtresi alt kategorileri kapsadığı için)
+func loadCategoryFacets(f productFilters) ([]models.CategoryFacet, error) {
+	where, args, _, _ := f.where()
+	rows, err := database.DB.Query(`
+		SELECT p.category_id, COUNT(DISTINCT p.id)
+	`+productFromSQL+where+`
+		  AND p.category_id IS NOT NULL
+		GROUP BY p.category_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	direct := map[int]int{}
+	for rows.Next() {
+		var categoryID, count int
+		if err := rows.Scan(&categoryID, &count); err != nil {
+			return nil, err
+		}
+		direct[categoryID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	categories, err := loadCategories(false)
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[int]*int, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	totals := map[int]int{}
+	for categoryID, count := range direct {
+		seen := map[int]bool{}
+		for id := &categoryID; id != nil && !seen[*id]; id = parents[*id] {
+			seen[*id] = true
+			totals[*id] += count
+		}
+	}
+
+	result := []models.CategoryFacet{}
+	for _, category := range categories {
+		if totals[category.ID] == 0 {
+			continue
+		}
+		result = append(result, models.CategoryFacet{
+			ID:       category.ID,
+			ParentID: category.ParentID,
+			Name:     category.Name,
+			Slug:     category.Slug,
+			Count:    totals[category.ID],
+		})
+	}
+	return result, nil
+}
+
+func loadPriceFacets(f productFilters) ([]models.PriceBucket, *models.PriceRange, error) {
+	where, args, _, _ := f.where()
+	args = append(args, pq.Array(priceFacetBounds))
+	boundsPlaceholder := "$" + strconv.Itoa(len(args))
+
+	rows, err := database.DB.Query(`
+		SELECT WIDTH_BUCKET(`+effectivePriceSQL+`::float8, `+boundsPlaceholder+`::float8[]) AS bucket,
+		       COUNT(DISTINCT p.id), MIN(`+effectivePriceSQL+`), MAX(`+effectivePriceSQL+`)
+	`+productFromSQL+where+`
+		GROUP BY bucket
+		ORDER BY bucket
+	`, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	buckets := []models.PriceBucket{}
+	var priceRange *models.PriceRange
+	for rows.Next() {
+		var bucket, count int
+		var minPrice, maxPrice float64
+		if err := rows.Scan(&bucket, &count, &minPrice, &maxPrice); err != nil {
+			return nil, nil, err
+		}
+
+		// WIDTH_BUCKET: 0 ilk sınırın altı, len(bounds) son sınırın üstü
+		b := models.PriceBucket{Count: count}
+		if bucket > 0 {
+			b.Min = priceFacetBounds[bucket-1]
+		}
+		if bucket < len(priceFacetBounds) {
+			upper := priceFacetBounds[bucket]
+			b.Max = &upper
+		}
+		buckets = append(buckets, b)
+
+		if priceRange == nil {
+			priceRange = &models.PriceRange{Min: minPrice, Max: maxPrice}
+		}
+		priceRange.Min = min(priceRange.Min, minPrice)
+		priceRange.Max = max(priceRange.Max, maxPrice)
+	}
+	return buckets, priceRange, rows.Err()
+}
+
+func loadStockFacets(f productFilters) ([]models.FacetValue, error) {
+	where, args, _, _ := f.where()
+	rows, err := database.DB.Query(`
+		SELECT `+stockStatusSQL+` AS status, COUNT(DISTINCT p.id)
+	`+productFromSQL+where+`
+		GROUP BY status
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.FacetValue, 0, len(stockStatuses))
+	for _, status := range stockStatuses {
+		result = append(result, models.FacetValue{Value: status, Count: counts[status]})
+	}
+	return result, nil
+}
+
+// loadAttributeFacets aktif varyantların seçenek değerlerini ve filtrelenebilir ürün
+// özelliklerinin değerlerini sayar. Filtrelenmemiş seçenekler tüm filtrelerle tek sorguda;
+// filtrelenen her seçenek kendi filtresi hariç ayrı sorguda sayılır.
+func loadAttributeFacets(f productFilters) ([]models.AttributeFacet, error) {
+	facets := map[string]*models.AttributeFacet{}
+
+	if err := collectAttributeFacets(f, "", f.Attributes, facets); err != nil {
+		return nil, err
+	}
+	if err := collectStructuredAttributeFacets(f, "", f.Attributes, facets); err != nil {
+		return nil, err
+	}
+	for name := range f.Attributes {
+		others := f
+		others.Attributes = make(map[string][]string, len(f.Attributes)-1)
+		for other, v := range f.Attributes {
+			if other != name {
+				others.Attributes[other] = v
+			}
+		}
+		if err := collectAttributeFacets(others, name, nil, facets); err != nil {
+			return nil, err
+		}
+		if err := collectStructuredAttributeFacets(others, name, nil, facets); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]models.AttributeFacet, 0, len(facets))
+	for _, facet := range facets {
+		facetValues := facet.Values
+		sort.Slice(facetValues, func(i, j int) bool {
+			if facetValues[i].Count != facetValues[j].Count {
+				return facetValues[i].Count > facetValues[j].Count
+			}
+			return facetValues[i].Value < facetValues[j].Value
+		})
+		if facet.Type == attributeTypeNumber {
+			for _, value := range facetValues {
+				n, err := strconv.ParseFloat(value.Value, 64)
+				if err != nil {
+					continue
+				}
+				if facet.Min == nil || n < *facet.Min {
+					facet.Min = &n
+				}
+				if facet.Max == nil || n > *facet.Max {
+					facet.Max = &n
+				}
+			}
+		}
+		result = append(result, *facet)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// attributeFacet ada göre facet'i bulur ya da oluşturur; seçenek ve özellik aynı adı
+// taşıyorsa değerleri tek facet'te birleşir
+func attributeFacet(into map[string]*models.AttributeFacet, name string) *models.AttributeFacet {
+	key := slugify(name)
+	facet, ok := into[key]
+	if !ok {
+		facet = &models.AttributeFacet{Name: name}
+		into[key] = facet
+	}
+	return facet
+}
+
+// collectAttributeFacets onlyName verilmişse yalnızca o seçeneği sayar; skip'teki
+// seçenekler (ayrıca sayılacakları için) atlanır
+func collectAttributeFacets(f productFilters, onlyName string, skip map[string][]string, into map[string]*models.AttributeFacet) error {
+	where, args, _, _ := f.where()
+	if onlyName != "" {
+		args = append(args, onlyName)
+		where += " AND search_normalize(o.name) = search_normalize($" + strconv.Itoa(len(args)) + ")"
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT o.name, ov.value, COUNT(DISTINCT p.id)
+	`+productFromSQL+`
+		JOIN product_variants v ON v.product_id = p.id AND v.is_active = true
+		JOIN product_variant_options vo ON vo.variant_id = v.id
+		JOIN product_option_values ov ON ov.id = vo.option_value_id
+		JOIN product_options o ON o.id = ov.option_id
+	`+where+`
+		GROUP BY o.name, ov.value
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, value string
+		var count int
+		if err := rows.Scan(&name, &value, &count); err != nil {
+			return err
+		}
+		if onlyName == "" && isFilteredAttribute(skip, name) {
+			continue
+		}
+		facet := attributeFacet(into, name)
+		facet.Values = append(facet.Values, models.FacetValue{Value: value, Count: count})
+	}
+	return rows.Err()
+}
+
+// collectStructuredAttributeFacets filtrelenebilir ürün özelliklerinin değerlerini sayar;
+// onlyName ve skip collectAttributeFacets ile aynı anlamdadır (ad veya kod ile eşleşir)
+func collectStructuredAttributeFacets(f productFilters, onlyName string, skip map[string][]string, into map[string]*models.AttributeFacet) error {
+	where, args, _, _ := f.where()
+	if onlyName != "" {
+		args = append(args, onlyName, slugify(onlyName))
+		where += " AND (ad.code = $" + strconv.Itoa(len(args)) +
+			" OR search_normalize(ad.name) = search_normalize($" + strconv.Itoa(len(args)-1) + "))"
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT ad.code, ad.name, ad.type, ad.unit, pav.value_text, COUNT(DISTINCT p.id)
+	`+productFromSQL+`
+		JOIN product_attribute_values pav ON pav.product_id = p.id
+		JOIN attribute_definitions ad ON ad.id = pav.attribute_id AND ad.is_filterable
+	`+where+`
+		GROUP BY ad.code, ad.name, ad.type, ad.unit, pav.value_text
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, name, attributeType, unit, value string
+		var count int
+		if err := rows.Scan(&code, &name, &attributeType, &unit, &value, &count); err != nil {
+			return err
+		}
+		if onlyName == "" && (isFilteredAttribute(skip, name) || isFilteredAttribute(skip, code)) {
+			continue
+		}
+		facet := attributeFacet(into, name)
+		facet.Code, facet.Type, facet.Unit = code, attributeType, unit
+		facet.Values = append(facet.Values, models.FacetValue{Value: value, Count: count})
+	}
+	return rows.Err()
+}
+
+// isFilteredAttribute seçenek adının filtrelerde (Türkçe/büyük-küçük harf duyarsız) geçip geçmediği
+func isFilteredAttribute(filters map[string][]string, name string) bool {
+	for filtered := range filters {
+		if slugify(filtered) == slugify(name) {
+			return true
+		}
+	}
+	return false
+}
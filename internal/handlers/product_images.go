@@ -0,0 +1,462 @@
+// ========================================
+// internal/handlers/product_images.go - ÜRÜN GÖRSEL GALERİSİ
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/imaging"
+	"ecommerce-backend/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// maxProductImageSize imaging.DefaultOptions().MaxBytes ile aynı; büyük dosyalar okunmadan reddedilir
+const maxProductImageSize = 5 * 1024 * 1024
+
+// GetProductImages ürünün galerisini sıralı döndürür
+func (h *ProductHandler) GetProductImages(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	images, err := loadProductImages(database.DB, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün görselleri alınamadı: " + err.Error()})
+		return
+	}
+	if images == nil {
+		images = []models.ProductImage{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// UploadProductImage multipart "image" dosyasını products bucket'ına yükler ve galeriye ekler.
+// Ürünün ilk görseli otomatik olarak birincil görsel olur.
+func (h *ProductHandler) UploadProductImage(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Görsel dosyası gerekli"})
+		return
+	}
+	defer file.Close()
+
+	// File size kontrolü (5MB)
+	if header.Size > maxProductImageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dosya boyutu çok büyük (max 5MB)"})
+		return
+	}
+
+	altText := strings.TrimSpace(c.PostForm("alt_text"))
+	if len(altText) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alternatif metin çok uzun (max 255)"})
+		return
+	}
+	makePrimary, _ := strconv.ParseBool(c.PostForm("is_primary"))
+
+	var productExists bool
+	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", productID).Scan(&productExists)
+	if err != nil || !productExists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	}
+
+	// Tip içerikten tespit edilir, EXIF temizlenir ve boyut varyantları üretilir
+	processed, err := imaging.Process(file, imaging.DefaultOptions())
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	// products/{productID}/{unique}/{varyant} içine yükle
+	bucket := h.cfg.ProductImagesBucket
+	prefix := fmt.Sprintf("%d/%d", productID, time.Now().UnixNano())
+	uploaded, err := uploadRenditions(c.Request.Context(), h.storage, bucket, prefix, processed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Görsel yüklenemedi: " + err.Error()})
+		return
+	}
+
+	image, err := insertProductImage(productID, uploaded, altText, makePrimary)
+	if err != nil {
+		// Geri al
+		_ = deleteObjects(c.Request.Context(), h.storage, bucket, uploaded.Paths)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Görsel kaydedilemedi: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Görsel başarıyla yüklendi",
+		"image":   image,
+	})
+}
+
+// UpdateProductImage alternatif metni günceller veya görseli birincil yapar
+func (h *ProductHandler) UpdateProductImage(c *gin.Context) {
+	productID, imageID, ok := productImageParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProductImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.IsPrimary != nil && !*req.IsPrimary {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Birincil görsel, başka bir görsel birincil yapılarak değiştirilir"})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err = lockProductGallery(tx, productID); err != nil {
+		respondGalleryLockError(c, err)
+		return
+	}
+
+	if req.AltText != nil {
+		result, err := tx.Exec(
+			"UPDATE product_images SET alt_text = $1 WHERE id = $2 AND product_id = $3",
+			strings.TrimSpace(*req.AltText), imageID, productID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Görsel güncellenemedi: " + err.Error()})
+			return
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Görsel bulunamadı"})
+			return
+		}
+	}
+
+	if req.IsPrimary != nil {
+		if err = setPrimaryProductImage(tx, productID, imageID); err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Görsel bulunamadı"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Birincil görsel ayarlanamadı: " + err.Error()})
+			return
+		}
+	}
+
+	images, err := loadProductImages(tx, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün görselleri alınamadı: " + err.Error()})
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// ReorderProductImages galerinin sırasını verilen ID listesine göre ayarlar.
+// Liste ürünün tüm görsellerini tam olarak bir kez içermelidir.
+func (h *ProductHandler) ReorderProductImages(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	var req models.ReorderProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err = lockProductGallery(tx, productID); err != nil {
+		respondGalleryLockError(c, err)
+		return
+	}
+
+	images, err := loadProductImages(tx, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün görselleri alınamadı: " + err.Error()})
+		return
+	}
+
+	existing := make(map[int]bool, len(images))
+	for _, image := range images {
+		existing[image.ID] = true
+	}
+	if len(req.ImageIDs) != len(images) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ürünün tüm görselleri sıralamaya dahil edilmelidir"})
+		return
+	}
+	for _, id := range req.ImageIDs {
+		if !existing[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Görsel bulunamadı veya tekrar ediyor", "image_id": id})
+			return
+		}
+		delete(existing, id)
+	}
+
+	for position, id := range req.ImageIDs {
+		if _, err = tx.Exec("UPDATE product_images SET position = $1 WHERE id = $2", position, id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Sıralama güncellenemedi: " + err.Error()})
+			return
+		}
+	}
+
+	images, err = loadProductImages(tx, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün görselleri alınamadı: " + err.Error()})
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// DeleteProductImage görseli galeriden ve storage'dan siler. Birincil görsel silinirse
+// sıradaki görsel birincil olur.
+func (h *ProductHandler) DeleteProductImage(c *gin.Context) {
+	productID, imageID, ok := productImageParams(c)
+	if !ok {
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err = lockProductGallery(tx, productID); err != nil {
+		respondGalleryLockError(c, err)
+		return
+	}
+
+	var objectPath string
+	var variantPaths []string
+	var wasPrimary bool
+	err = tx.QueryRow(
+		"DELETE FROM product_images WHERE id = $1 AND product_id = $2 RETURNING object_path, variant_paths, is_primary",
+		imageID, productID,
+	).Scan(&objectPath, pq.Array(&variantPaths), &wasPrimary)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Görsel bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Görsel silinemedi: " + err.Error()})
+		return
+	}
+
+	if wasPrimary {
+		var nextID int
+		err = tx.QueryRow(
+			"SELECT id FROM product_images WHERE product_id = $1 ORDER BY position, id LIMIT 1",
+			productID,
+		).Scan(&nextID)
+		switch {
+		case err == sql.ErrNoRows:
+			_, err = tx.Exec("UPDATE products SET image = '', updated_at = NOW() WHERE id = $1", productID)
+		case err == nil:
+			err = setPrimaryProductImage(tx, productID, nextID)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Birincil görsel güncellenemedi: " + err.Error()})
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	// Kayıt silindi; storage nesneleri silinemezse yalnızca raporlanır
+	paths := variantPaths
+	if len(paths) == 0 {
+		paths = []string{objectPath}
+	}
+	response := gin.H{"message": "Görsel silindi", "image_id": imageID}
+	if err := deleteObjects(c.Request.Context(), h.storage, h.cfg.ProductImagesBucket, paths); err != nil {
+		fmt.Printf("Product image storage cleanup failed: %s: %v\n", objectPath, err)
+		response["storage_cleanup_failed"] = true
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// productImageParams :id ve :imageId parametrelerini okur; hatalıysa yanıtı yazar
+func productImageParams(c *gin.Context) (int, int, bool) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return 0, 0, false
+	}
+	imageID, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz image ID"})
+		return 0, 0, false
+	}
+	return productID, imageID, true
+}
+
+// lockProductGallery ürün satırını kilitleyerek aynı ürünün galeri
+// değişikliklerini (sıra, birincil görsel) sıraya sokar
+func lockProductGallery(tx *sql.Tx, productID int) error {
+	var id int
+	return tx.QueryRow("SELECT id FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&id)
+}
+
+func respondGalleryLockError(c *gin.Context, err error) {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün kilitlenemedi: " + err.Error()})
+}
+
+// insertProductImage yüklenen görseli galerinin sonuna ekler
+func insertProductImage(productID int, uploaded *uploadedImage, altText string, makePrimary bool) (models.ProductImage, error) {
+	var image models.ProductImage
+	variantsJSON, err := json.Marshal(uploaded.Variants)
+	if err != nil {
+		return image, err
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return image, err
+	}
+	defer tx.Rollback()
+
+	if err = lockProductGallery(tx, productID); err != nil {
+		return image, err
+	}
+
+	var hasPrimary bool
+	err = tx.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM product_images WHERE product_id = $1 AND is_primary)",
+		productID,
+	).Scan(&hasPrimary)
+	if err != nil {
+		return image, err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO product_images (product_id, object_path, url, variants, variant_paths, alt_text, position, is_primary, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6,
+		        (SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1),
+		        false, NOW())
+		RETURNING id, product_id, object_path, url, alt_text, position, is_primary, created_at
+	`, productID, uploaded.Path, uploaded.URL, variantsJSON, pq.Array(uploaded.Paths), altText).Scan(
+		&image.ID, &image.ProductID, &image.ObjectPath, &image.URL, &image.AltText,
+		&image.Position, &image.IsPrimary, &image.CreatedAt,
+	)
+	if err != nil {
+		return image, err
+	}
+	image.Variants = uploaded.Variants
+
+	if makePrimary || !hasPrimary {
+		if err = setPrimaryProductImage(tx, productID, image.ID); err != nil {
+			return image, err
+		}
+		image.IsPrimary = true
+	}
+
+	return image, tx.Commit()
+}
+
+// setPrimaryProductImage görseli birincil yapar ve URL'ini products.image alanına yazar
+func setPrimaryProductImage(tx *sql.Tx, productID, imageID int) error {
+	if _, err := tx.Exec(
+		"UPDATE product_images SET is_primary = false WHERE product_id = $1 AND is_primary AND id <> $2",
+		productID, imageID,
+	); err != nil {
+		return err
+	}
+
+	var url string
+	err := tx.QueryRow(
+		"UPDATE product_images SET is_primary = true WHERE id = $1 AND product_id = $2 RETURNING url",
+		imageID, productID,
+	).Scan(&url)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE products SET image = $1, updated_at = NOW() WHERE id = $2", url, productID)
+	return err
+}
+
+// queryer *sql.DB ve *sql.Tx için ortak çok satırlı sorgu arayüzü
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadProductImages ürünün görsellerini sıralı döndürür
+func loadProductImages(q queryer, productID int) ([]models.ProductImage, error) {
+	rows, err := q.Query(`
+		SELECT id, product_id, object_path, url, variants, alt_text, position, is_primary, created_at
+		FROM product_images
+		WHERE product_id = $1
+		ORDER BY position, id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []models.ProductImage
+	for rows.Next() {
+		var image models.ProductImage
+		var variants []byte
+		err := rows.Scan(
+			&image.ID, &image.ProductID, &image.ObjectPath, &image.URL, &variants, &image.AltText,
+			&image.Position, &image.IsPrimary, &image.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(variants, &image.Variants); err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, rows.Err()
+}
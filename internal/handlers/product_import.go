@@ -0,0 +1,740 @@
+// ========================================
+// internal/handlers/product_import.go - TOPLU ÜRÜN İÇE AKTARMA (CSV / JSON LINES)
+// ========================================
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxProductImportSize içe aktarma dosyasının en büyük boyutu (20MB)
+	maxProductImportSize = 20 << 20
+	// productImportBatchSize her transaction'da işlenen satır sayısı
+	productImportBatchSize = 500
+	// productImportLockKey eşzamanlı içe aktarmaları sıraya sokan advisory lock anahtarı
+	productImportLockKey = "product_import"
+)
+
+const (
+	importFormatCSV   = "csv"
+	importFormatJSONL = "jsonl"
+)
+
+// productImportColumns CSV başlığında ve JSON alanlarında kabul edilen sütunlar
+var productImportColumns = []string{
+	"sku", "title", "description", "price", "image", "category", "category_id",
+	"is_active", "stock", "min_stock_level", "max_stock_level", "cost_price",
+}
+
+// productImportRow dosyadaki tek satır; nil alanlar dosyada verilmemiştir ve mevcut
+// üründe değiştirilmez. Yeni ürün için title ve price zorunludur.
+type productImportRow struct {
+	Line          int      `json:"-"`
+	SKU           string   `json:"sku"`
+	Title         *string  `json:"title"`
+	Description   *string  `json:"description"`
+	Price         *float64 `json:"price"`
+	Image         *string  `json:"image"`
+	Category      *string  `json:"category"`
+	CategoryID    *int     `json:"category_id"`
+	IsActive      *bool    `json:"is_active"`
+	Stock         *int     `json:"stock"`
+	MinStockLevel *int     `json:"min_stock_level"`
+	MaxStockLevel *int     `json:"max_stock_level"`
+	CostPrice     *float64 `json:"cost_price"`
+
+	// parseErr satır okunurken oluşan hata; böyle satırlar yazılmadan raporlanır
+	parseErr string
+}
+
+// validate veritabanına gitmeden kontrol edilebilen kuralları uygular
+func (r *productImportRow) validate() error {
+	switch {
+	case r.SKU == "":
+		return errors.New("sku zorunludur")
+	case r.Title != nil && strings.TrimSpace(*r.Title) == "":
+		return errors.New("title boş olamaz")
+	case r.Price != nil && *r.Price < 0:
+		return errors.New("price negatif olamaz")
+	case r.CostPrice != nil && *r.CostPrice < 0:
+		return errors.New("cost_price negatif olamaz")
+	case r.Stock != nil && *r.Stock < 0:
+		return errors.New("stock negatif olamaz")
+	case r.MinStockLevel != nil && *r.MinStockLevel < 0:
+		return errors.New("min_stock_level negatif olamaz")
+	case r.MaxStockLevel != nil && *r.MaxStockLevel < 0:
+		return errors.New("max_stock_level negatif olamaz")
+	}
+	return nil
+}
+
+// ImportProducts CSV veya JSON lines dosyasındaki ürünleri SKU ile eşleştirerek oluşturur ya da
+// günceller. Dosya multipart "file" alanında veya doğrudan istek gövdesinde gönderilebilir.
+// Format ?format=csv|jsonl ile ya da dosya uzantısı / Content-Type'tan belirlenir.
+// ?dry_run=true ile satırlar doğrulanır ama hiçbir değişiklik kaydedilmez.
+// İş arka planda çalışır; ilerleme GET /products/imports/:importId ile izlenir.
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	userID := c.GetString("userID")
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", c.PostForm("dry_run")))
+	allowBelowCost, _ := strconv.ParseBool(c.DefaultQuery("allow_below_cost", c.PostForm("allow_below_cost")))
+
+	var data []byte
+	var fileName string
+	var err error
+	contentType := c.ContentType()
+	if strings.HasPrefix(contentType, "multipart/") {
+		file, header, ferr := c.Request.FormFile("file")
+		if ferr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "İçe aktarma dosyası gerekli (file)"})
+			return
+		}
+		defer file.Close()
+		if header.Size > maxProductImportSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Dosya boyutu çok büyük (max 20MB)"})
+			return
+		}
+		fileName = header.Filename
+		contentType = header.Header.Get("Content-Type")
+		data, err = io.ReadAll(io.LimitReader(file, maxProductImportSize+1))
+	} else {
+		data, err = io.ReadAll(io.LimitReader(c.Request.Body, maxProductImportSize+1))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dosya okunamadı: " + err.Error()})
+		return
+	}
+	if len(data) > maxProductImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dosya boyutu çok büyük (max 20MB)"})
+		return
+	}
+
+	format := detectImportFormat(c.Query("format"), fileName, contentType)
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dosya formatı belirlenemedi (format=csv veya format=jsonl)"})
+		return
+	}
+
+	var rows []productImportRow
+	if format == importFormatCSV {
+		rows, err = parseProductImportCSV(data)
+	} else {
+		rows, err = parseProductImportJSONL(data)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dosyada içe aktarılacak satır yok"})
+		return
+	}
+
+	var imp models.ProductImport
+	err = database.DB.QueryRow(`
+		INSERT INTO product_imports (user_id, format, file_name, dry_run, allow_below_cost, total_rows)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, user_id, format, file_name, dry_run, allow_below_cost, status, total_rows, created_at
+	`, userID, format, fileName, dryRun, allowBelowCost, len(rows)).Scan(
+		&imp.ID, &imp.UserID, &imp.Format, &imp.FileName, &imp.DryRun, &imp.AllowBelowCost, &imp.Status, &imp.TotalRows, &imp.CreatedAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İçe aktarma başlatılamadı: " + err.Error()})
+		return
+	}
+
+	go runProductImport(imp.ID, rows, productImportOptions{DryRun: dryRun, AllowBelowCost: allowBelowCost, UserID: userID})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "İçe aktarma başlatıldı",
+		"import":     imp,
+		"status_url": fmt.Sprintf("/api/v1/products/imports/%d", imp.ID),
+	})
+}
+
+// GetProductImports son içe aktarma işlerini (satır hataları olmadan) listeler
+func (h *ProductHandler) GetProductImports(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > maxProductPageSize {
+		limit = 20
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT `+productImportColumnsSQL+`
+		FROM product_imports
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İçe aktarmalar alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	imports := []models.ProductImport{}
+	for rows.Next() {
+		imp, err := scanProductImport(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "İçe aktarmalar okunamadı: " + err.Error()})
+			return
+		}
+		imports = append(imports, *imp)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imports": imports})
+}
+
+// GetProductImport içe aktarma işinin durumunu, ilerlemesini ve satır hata raporunu döner
+func (h *ProductHandler) GetProductImport(c *gin.Context) {
+	importID, err := strconv.Atoi(c.Param("importId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz import ID"})
+		return
+	}
+
+	var rowErrors []byte
+	imp, err := scanProductImport(database.DB.QueryRow(`
+		SELECT `+productImportColumnsSQL+`, row_errors
+		FROM product_imports WHERE id = $1
+	`, importID), &rowErrors)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "İçe aktarma bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İçe aktarma alınamadı: " + err.Error()})
+		return
+	}
+	imp.RowErrors = []models.ProductImportRowError{}
+	if err := json.Unmarshal(rowErrors, &imp.RowErrors); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hata raporu okunamadı: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"import": imp})
+}
+
+const productImportColumnsSQL = `id, user_id, format, file_name, dry_run, allow_below_cost, status, total_rows, processed_rows,
+		       created_count, updated_count, failed_count, error_message, created_at, started_at, finished_at`
+
+// scanProductImport productImportColumnsSQL sırasındaki sütunları okur; extra sonraki sütunlar içindir
+func scanProductImport(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*models.ProductImport, error) {
+	var imp models.ProductImport
+	dest := append([]interface{}{
+		&imp.ID, &imp.UserID, &imp.Format, &imp.FileName, &imp.DryRun, &imp.AllowBelowCost, &imp.Status, &imp.TotalRows, &imp.ProcessedRows,
+		&imp.CreatedCount, &imp.UpdatedCount, &imp.FailedCount, &imp.ErrorMessage, &imp.CreatedAt, &imp.StartedAt, &imp.FinishedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if imp.TotalRows > 0 {
+		imp.Progress = float64(imp.ProcessedRows) / float64(imp.TotalRows)
+	}
+	return &imp, nil
+}
+
+// FailInterruptedProductImports sunucu yeniden başlarken yarım kalan işleri başarısız işaretler.
+// Tamamlanan batch'ler kaydedilmiştir; dosya yeniden yüklenirse SKU eşleşmesi sayesinde
+// aynı satırlar güncelleme olarak tekrar işlenir.
+func FailInterruptedProductImports() error {
+	_, err := database.DB.Exec(`
+		UPDATE product_imports
+		SET status = 'failed', error_message = 'Sunucu yeniden başlatıldığı için içe aktarma yarıda kaldı', finished_at = NOW()
+		WHERE status IN ('pending', 'running')
+	`)
+	return err
+}
+
+// detectImportFormat açık format parametresini, yoksa dosya uzantısını ve Content-Type'ı kullanır
+func detectImportFormat(explicit, fileName, contentType string) string {
+	switch strings.ToLower(strings.TrimSpace(explicit)) {
+	case "csv":
+		return importFormatCSV
+	case "jsonl", "ndjson", "json":
+		return importFormatJSONL
+	case "":
+	default:
+		return ""
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return importFormatCSV
+	case ".jsonl", ".ndjson", ".json":
+		return importFormatJSONL
+	}
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return importFormatCSV
+	case strings.Contains(contentType, "ndjson"), strings.Contains(contentType, "jsonl"), strings.Contains(contentType, "json"):
+		return importFormatJSONL
+	}
+	return ""
+}
+
+// parseProductImportCSV başlık satırındaki sütun adlarıyla satırları okur. Ayraç virgül veya
+// (Excel'in Türkçe yerel ayarındaki gibi) noktalı virgül olabilir. Boş hücre "verilmedi" sayılır.
+func parseProductImportCSV(data []byte) ([]productImportRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("CSV başlığı okunamadı: " + err.Error())
+	}
+	columns := make([]string, len(header))
+	hasSKU := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isProductImportColumn(name) {
+			return nil, fmt.Errorf("Bilinmeyen CSV sütunu: %q (geçerli sütunlar: %s)", header[i], strings.Join(productImportColumns, ", "))
+		}
+		hasSKU = hasSKU || name == "sku"
+		columns[i] = name
+	}
+	if !hasSKU {
+		return nil, errors.New("CSV başlığında sku sütunu zorunludur")
+	}
+
+	var rows []productImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("CSV okunamadı: " + err.Error())
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := productImportRow{Line: line}
+		for i, value := range record[:min(len(record), len(columns))] {
+			if err := row.set(columns[i], strings.TrimSpace(value)); err != nil && row.parseErr == "" {
+				row.parseErr = err.Error()
+			}
+		}
+		if len(record) != len(columns) {
+			row.parseErr = fmt.Sprintf("Sütun sayısı hatalı: %d beklenirken %d", len(columns), len(record))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseProductImportJSONL her satırı ayrı bir JSON nesnesi olarak okur; boş satırlar atlanır
+func parseProductImportJSONL(data []byte) ([]productImportRow, error) {
+	var rows []productImportRow
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxProductImportSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if line == 1 {
+			text = bytes.TrimPrefix(text, []byte("\xef\xbb\xbf"))
+		}
+		if len(text) == 0 {
+			continue
+		}
+
+		row := productImportRow{}
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			row = productImportRow{parseErr: "Geçersiz JSON: " + err.Error()}
+		}
+		row.Line = line
+		row.SKU = strings.TrimSpace(row.SKU)
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("Dosya okunamadı: " + err.Error())
+	}
+	return rows, nil
+}
+
+func isProductImportColumn(name string) bool {
+	for _, column := range productImportColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// set CSV hücresini ilgili alana yazar; boş hücre alanı nil bırakır
+func (r *productImportRow) set(column, value string) error {
+	if value == "" {
+		return nil
+	}
+	var err error
+	switch column {
+	case "sku":
+		r.SKU = value
+	case "title":
+		r.Title = &value
+	case "description":
+		r.Description = &value
+	case "image":
+		r.Image = &value
+	case "category":
+		r.Category = &value
+	case "price":
+		r.Price, err = parseDecimal(value)
+	case "cost_price":
+		r.CostPrice, err = parseDecimal(value)
+	case "category_id":
+		r.CategoryID, err = parseImportInt(value)
+	case "stock":
+		r.Stock, err = parseImportInt(value)
+	case "min_stock_level":
+		r.MinStockLevel, err = parseImportInt(value)
+	case "max_stock_level":
+		r.MaxStockLevel, err = parseImportInt(value)
+	case "is_active":
+		var b bool
+		switch strings.ToLower(value) {
+		case "1", "true", "yes", "evet", "aktif":
+			b = true
+		case "0", "false", "no", "hayır", "hayir", "pasif":
+			b = false
+		default:
+			return fmt.Errorf("is_active geçersiz: %q", value)
+		}
+		r.IsActive = &b
+	}
+	if err != nil {
+		return fmt.Errorf("%s geçersiz: %q", column, value)
+	}
+	return nil
+}
+
+// parseDecimal ondalık ayracı nokta veya (nokta içermiyorsa) virgül olan sonlu sayıları okur
+func parseDecimal(value string) (*float64, error) {
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, strconv.ErrSyntax
+	}
+	return &f, nil
+}
+
+func parseImportInt(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// productImportStats bir batch'in sonuçları
+type productImportStats struct {
+	Processed int
+	Created   int
+	Updated   int
+	RowErrors []models.ProductImportRowError
+}
+
+// productImportOptions içe aktarma işinin ayarları
+type productImportOptions struct {
+	DryRun bool
+	// Maliyetin altındaki fiyatlar satır hatası yerine onaylı değişiklik olarak yazılır
+	AllowBelowCost bool
+	UserID         string
+}
+
+// runProductImport satırları batch'ler halinde işler ve her batch sonunda ilerlemeyi kaydeder.
+// Her batch kendi transaction'ında yazılır; hatalı satır savepoint ile geri alınır, batch'in
+// geri kalanı etkilenmez. Dry-run da aynı batch'lerle çalışır ancak her batch sonunda geri
+// alınır; böylece büyük dosyalarda kilitler dosyanın tamamı boyunca tutulmaz.
+func runProductImport(importID int, rows []productImportRow, opts productImportOptions) {
+	if _, err := database.DB.Exec(
+		"UPDATE product_imports SET status = 'running', started_at = NOW() WHERE id = $1", importID,
+	); err != nil {
+		log.Printf("Product import %d could not start: %v", importID, err)
+		return
+	}
+
+	for start := 0; start < len(rows); start += productImportBatchSize {
+		batch := rows[start:min(start+productImportBatchSize, len(rows))]
+
+		stats, err := importProductBatchTx(batch, opts)
+		if err != nil {
+			finishProductImport(importID, err)
+			return
+		}
+		if err = saveProductImportProgress(importID, stats); err != nil {
+			log.Printf("Product import %d progress could not be saved: %v", importID, err)
+		}
+	}
+
+	finishProductImport(importID, nil)
+}
+
+// importProductBatchTx batch'i kendi transaction'ında yazar. Eşzamanlı içe aktarmalar aynı
+// SKU için çift ürün oluşturmasın diye advisory lock ile sıraya sokulur; dry-run da aynı kilidi
+// alır ki sonuçları gerçek bir içe aktarmayla aynı sırada hesaplansın. Dry-run'da batch commit
+// edilmez, defer'daki rollback ile geri alınır.
+func importProductBatchTx(batch []productImportRow, opts productImportOptions) (productImportStats, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return productImportStats{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", productImportLockKey); err != nil {
+		return productImportStats{}, err
+	}
+	stats, err := importProductBatch(tx, batch, opts)
+	if err != nil || opts.DryRun {
+		return stats, err
+	}
+	return stats, tx.Commit()
+}
+
+func importProductBatch(tx *sql.Tx, batch []productImportRow, opts productImportOptions) (productImportStats, error) {
+	var stats productImportStats
+	for i := range batch {
+		row := &batch[i]
+		stats.Processed++
+
+		rowErr := row.parseErr
+		if rowErr == "" {
+			if err := row.validate(); err != nil {
+				rowErr = err.Error()
+			}
+		}
+		if rowErr == "" {
+			if _, err := tx.Exec("SAVEPOINT import_row"); err != nil {
+				return stats, err
+			}
+			created, err := upsertImportedProduct(tx, row, opts)
+			if err != nil {
+				if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT import_row"); rbErr != nil {
+					return stats, rbErr
+				}
+				rowErr = err.Error()
+			} else {
+				if _, err := tx.Exec("RELEASE SAVEPOINT import_row"); err != nil {
+					return stats, err
+				}
+				if created {
+					stats.Created++
+				} else {
+					stats.Updated++
+				}
+			}
+		}
+		if rowErr != "" {
+			stats.RowErrors = append(stats.RowErrors, models.ProductImportRowError{Row: row.Line, SKU: row.SKU, Error: rowErr})
+		}
+	}
+	return stats, nil
+}
+
+// upsertImportedProduct SKU ile eşleşen ürünü günceller, yoksa oluşturur; ardından stok
+// alanları verilmişse inventory kaydını yazar. Arşivlenmiş ürünler de eşleşir ve arşivde kalır.
+func upsertImportedProduct(tx *sql.Tx, row *productImportRow, opts productImportOptions) (bool, error) {
+	rows, err := tx.Query("SELECT id FROM products WHERE sku = $1 ORDER BY id FOR UPDATE", row.SKU)
+	if err != nil {
+		return false, err
+	}
+	var ids []string
+	var productID int
+	for rows.Next() {
+		if err := rows.Scan(&productID); err != nil {
+			rows.Close()
+			return false, err
+		}
+		ids = append(ids, strconv.Itoa(productID))
+	}
+	rows.Close()
+	if len(ids) > 1 {
+		return false, fmt.Errorf("SKU birden fazla üründe kullanılıyor (id: %s)", strings.Join(ids, ", "))
+	}
+
+	setCategory := row.Category != nil || row.CategoryID != nil
+	var categoryID *int
+	var categoryName string
+	if setCategory {
+		name := ""
+		if row.Category != nil {
+			name = *row.Category
+		}
+		if categoryID, categoryName, err = resolveProductCategory(tx, row.CategoryID, name); err != nil {
+			if errors.Is(err, errCategoryNotFound) {
+				return false, err
+			}
+			return false, errors.New("Kategori alınamadı: " + err.Error())
+		}
+	}
+
+	created := len(ids) == 0
+	if created {
+		if row.Title == nil || row.Price == nil {
+			return false, errors.New("Yeni ürün için title ve price zorunludur")
+		}
+		isActive := row.IsActive == nil || *row.IsActive
+		slug, serr := uniqueProductSlug(tx, slugOrDefault(*row.Title), 0)
+		if serr != nil {
+			return false, errors.New("Slug üretilemedi: " + serr.Error())
+		}
+		err = tx.QueryRow(`
+			INSERT INTO products (title, description, price, image, category, category_id, sku, rating, rating_count, is_active,
+			                      slug, created_at, updated_at)
+			VALUES ($1, COALESCE($2, ''), $3, COALESCE($4, ''), $5, $6, $7, 0, 0, $8, $9, NOW(), NOW())
+			RETURNING id
+		`, *row.Title, row.Description, *row.Price, row.Image, categoryName, categoryID, row.SKU, isActive, slug).Scan(&productID)
+	} else {
+		_, err = tx.Exec(`
+			UPDATE products SET
+				title = COALESCE($2, title),
+				description = COALESCE($3, description),
+				price = COALESCE($4, price),
+				image = COALESCE($5, image),
+				is_active = COALESCE($6, is_active),
+				category = CASE WHEN $7 THEN $8 ELSE category END,
+				category_id = CASE WHEN $7 THEN $9::int ELSE category_id END,
+				updated_at = NOW()
+			WHERE id = $1
+		`, productID, row.Title, row.Description, row.Price, row.Image, row.IsActive, setCategory, categoryName, categoryID)
+	}
+	if err != nil {
+		return false, errors.New("Ürün kaydedilemedi: " + err.Error())
+	}
+
+	// Yeni ürünün her zaman inventory kaydı olur (CreateProduct ile aynı); mevcut üründe
+	// yalnızca verilen stok alanları değişir
+	if created || row.Stock != nil || row.MinStockLevel != nil || row.MaxStockLevel != nil || row.CostPrice != nil {
+		var quantity, reserved int
+		err = tx.QueryRow(`
+			INSERT INTO inventory (product_id, quantity, reserved_quantity, min_stock_level, max_stock_level, cost_price, updated_at)
+			VALUES ($1, COALESCE($2, 0), 0, COALESCE($3, 0), COALESCE($4, 0), COALESCE($5, 0), NOW())
+			ON CONFLICT (product_id) DO UPDATE SET
+			  quantity = COALESCE($2, inventory.quantity),
+			  min_stock_level = COALESCE($3, inventory.min_stock_level),
+			  max_stock_level = COALESCE($4, inventory.max_stock_level),
+			  cost_price = COALESCE($5, inventory.cost_price),
+			  updated_at = NOW()
+			RETURNING quantity, reserved_quantity
+		`, productID, row.Stock, row.MinStockLevel, row.MaxStockLevel, row.CostPrice).Scan(&quantity, &reserved)
+		if err != nil {
+			return false, errors.New("Stok kaydedilemedi: " + err.Error())
+		}
+		if quantity < reserved {
+			return false, fmt.Errorf("stock (%d) rezerve edilmiş miktarın (%d) altında olamaz", quantity, reserved)
+		}
+	}
+
+	// Fiyat değiştiyse indirimli fiyat ve maliyet kontrolü yapılır, değişiklik fiyat geçmişine yazılır
+	if row.Price != nil {
+		var price float64
+		var salePrice *float64
+		if err = tx.QueryRow("SELECT COALESCE(price, 0), sale_price FROM products WHERE id = $1", productID).Scan(&price, &salePrice); err != nil {
+			return false, err
+		}
+		if err = validateSalePrice(price, salePrice, nil, nil); err != nil {
+			return false, err
+		}
+		belowCost, err := guardPriceAboveCost(tx, productID, opts.AllowBelowCost)
+		if err != nil {
+			return false, err
+		}
+		if err = recordPriceChange(tx, productID, priceSourceImport, opts.UserID, belowCost); err != nil {
+			return false, errors.New("Fiyat geçmişi yazılamadı: " + err.Error())
+		}
+	}
+
+	// Yeni ürünlerde ve kategorisi değişen ürünlerde kategorinin zorunlu özellikleri kontrol edilir
+	// (CreateProduct / UpdateProduct ile aynı); yeni kategoride tanımlı olmayan değerler kaldırılır
+	if created || setCategory {
+		if err = saveProductAttributes(tx, productID, categoryID, nil, true); err != nil {
+			if attrErr, ok := err.(*attributeError); ok {
+				return false, errors.New(attrErr.Message)
+			}
+			return false, errors.New("Ürün özellikleri güncellenemedi: " + err.Error())
+		}
+	}
+	return created, nil
+}
+
+func saveProductImportProgress(importID int, stats productImportStats) error {
+	rowErrors := stats.RowErrors
+	if rowErrors == nil {
+		rowErrors = []models.ProductImportRowError{}
+	}
+	rowErrorsJSON, err := json.Marshal(rowErrors)
+	if err != nil {
+		return err
+	}
+	_, err = database.DB.Exec(`
+		UPDATE product_imports SET
+			processed_rows = processed_rows + $2,
+			created_count = created_count + $3,
+			updated_count = updated_count + $4,
+			failed_count = failed_count + $5,
+			row_errors = row_errors || $6::jsonb
+		WHERE id = $1
+	`, importID, stats.Processed, stats.Created, stats.Updated, len(stats.RowErrors), string(rowErrorsJSON))
+	return err
+}
+
+// finishProductImport işi tamamlandı (jobErr nil) veya başarısız olarak işaretler
+func finishProductImport(importID int, jobErr error) {
+	var err error
+	if jobErr == nil {
+		_, err = database.DB.Exec(
+			"UPDATE product_imports SET status = 'completed', finished_at = NOW() WHERE id = $1", importID,
+		)
+	} else {
+		log.Printf("Product import %d failed: %v", importID, jobErr)
+		_, err = database.DB.Exec(
+			"UPDATE product_imports SET status = 'failed', error_message = $2, finished_at = NOW() WHERE id = $1",
+			importID, jobErr.Error(),
+		)
+	}
+	if err != nil {
+		log.Printf("Product import %d status could not be saved: %v", importID, err)
+	}
+}
@@ -0,0 +1,394 @@
+// ========================================
+// internal/handlers/product_list.go - ÜRÜN LİSTELEME SORGUSU (ORTAK FİLTRELER)
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// productFromSQL listeleme, sayım ve facet sorgularının ortak FROM kısmı;
+// stok filtreleri inventory tablosunu "i" alias'ı ile bekler
+const productFromSQL = `
+		FROM products p
+		LEFT JOIN inventory i ON p.id = i.product_id
+	`
+
+// productFilters GetProducts ve GetProductsCount'un ortak filtre parametreleri
+type productFilters struct {
+	Search string
+	// Fuzzy true ise arama tam metin yerine trigram benzerliğiyle yapılır (yazım hatalarına toleranslı)
+	Fuzzy       bool
+	CategoryID  int
+	Category    string
+	StockFilter string
+
+	// EKLEME: Fiyat/puan aralığı, çoklu kategori (slug veya id) ve seçenek filtreleri.
+	// Attributes seçenek veya özellik adı (ya da özellik kodu) -> kabul edilen değerler
+	// (ör: Renk -> [Kırmızı, Mavi], agirlik -> [1..5]); aynı seçenek içindeki değerler VEYA,
+	// farklı seçenekler VE ile birleşir.
+	MinPrice   *float64
+	MaxPrice   *float64
+	MinRating  *float64
+	Categories []string
+	Attributes map[string][]string
+
+	// Visibility boşsa yalnızca aktif ve arşivlenmemiş ürünler listelenir (public katalog).
+	// Yönetim listesi için: productVisibilityAll, Active, Inactive, Archived
+	Visibility string
+}
+
+const (
+	productVisibilityAll      = "all"
+	productVisibilityActive   = "active"
+	productVisibilityInactive = "inactive"
+	productVisibilityArchived = "archived"
+)
+
+func productFiltersFromQuery(c *gin.Context) (productFilters, error) {
+	categoryID, err := categoryIDQuery(c)
+	if err != nil {
+		return productFilters{}, err
+	}
+	f := productFilters{
+		Search:      c.Query("search"),
+		Fuzzy:       c.Query("fuzzy") == "true",
+		CategoryID:  categoryID,
+		Category:    c.Query("category"),
+		StockFilter: c.Query("stock_filter"),
+		Categories:  splitQueryValues(c.QueryArray("categories")),
+	}
+
+	for param, dest := range map[string]**float64{"min_price": &f.MinPrice, "max_price": &f.MaxPrice, "min_rating": &f.MinRating} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return productFilters{}, errors.New("Geçersiz " + param)
+		}
+		*dest = &v
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return productFilters{}, errors.New("min_price, max_price değerinden büyük olamaz")
+	}
+
+	// ?attr[Renk]=Kırmızı,Mavi&attr[Beden]=M
+	for name, raw := range c.QueryMap("attr") {
+		name = strings.TrimSpace(name)
+		values := splitQueryValues([]string{raw})
+		if name == "" || len(values) == 0 {
+			continue
+		}
+		if f.Attributes == nil {
+			f.Attributes = map[string][]string{}
+		}
+		f.Attributes[name] = values
+	}
+	return f, nil
+}
+
+// splitQueryValues tekrar eden ve virgülle ayrılmış parametre değerlerini tek listede toplar
+func splitQueryValues(raw []string) []string {
+	var values []string
+	for _, item := range raw {
+		for _, value := range strings.Split(item, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// where filtrelerden WHERE koşulunu ve parametreleri üretir. Arama varsa
+// eşleşme skorunu hesaplayan ifade ve arama parametresinin yer tutucusu da döner.
+func (f productFilters) where() (where string, args []interface{}, rankSQL, searchPlaceholder string) {
+	switch f.Visibility {
+	case productVisibilityAll:
+		where = " WHERE TRUE"
+	case productVisibilityInactive:
+		where = " WHERE p.is_active = false AND p.deleted_at IS NULL"
+	case productVisibilityArchived:
+		where = " WHERE p.deleted_at IS NOT NULL"
+	default:
+		where = " WHERE p.is_active = true AND p.deleted_at IS NULL"
+	}
+	rankSQL = "0::float8"
+
+	if f.Fuzzy {
+		where, args, searchPlaceholder = appendFuzzySearchFilter(where, args, f.Search)
+		if searchPlaceholder != "" {
+			rankSQL = fuzzyRankSQL(searchPlaceholder)
+		}
+	} else {
+		// Tam metin araması (ağırlıklı, Türkçe + unaccent)
+		where, args, searchPlaceholder = appendSearchFilter(where, args, f.Search)
+		if searchPlaceholder != "" {
+			rankSQL = searchRankSQL(searchPlaceholder)
+		}
+	}
+
+	// Kategori filtresi alt kategorileri de kapsar
+	where, args = appendCategoryFilter(where, args, f.CategoryID, f.Category)
+	if len(f.Categories) > 0 {
+		args = append(args, pq.Array(f.Categories))
+		placeholder := "$" + strconv.Itoa(len(args))
+		where += " AND p.category_id IN (" + categorySubtreeSQL("slug = ANY("+placeholder+") OR id::text = ANY("+placeholder+")") + ")"
+	}
+
+	if f.MinPrice != nil {
+		args = append(args, *f.MinPrice)
+		where += " AND " + effectivePriceSQL + " >= $" + strconv.Itoa(len(args))
+	}
+	if f.MaxPrice != nil {
+		args = append(args, *f.MaxPrice)
+		where += " AND " + effectivePriceSQL + " <= $" + strconv.Itoa(len(args))
+	}
+	if f.MinRating != nil {
+		args = append(args, *f.MinRating)
+		where += " AND COALESCE(p.rating, 0) >= $" + strconv.Itoa(len(args))
+	}
+
+	// Seçenek / özellik filtreleri: ürünün en az bir aktif varyantı seçilen değerlerden birine
+	// sahip olmalı ya da ürünün aynı ada (veya koda) sahip filtrelenebilir özelliğinin değeri
+	// eşleşmeli (büyük/küçük harf ve Türkçe karakter duyarsız). number tipindeki özelliklerde
+	// "1..5" biçiminde aralık da verilebilir.
+	// Sıra sabit olsun diye isimler sıralanır (aynı filtre = aynı SQL).
+	names := make([]string, 0, len(f.Attributes))
+	for name := range f.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		exact, numbers, ranges := splitAttributeFilterValues(f.Attributes[name])
+		args = append(args, name, pq.Array(exact), slugify(name), pq.Array(numbers))
+		namePlaceholder := "$" + strconv.Itoa(len(args)-3)
+		valuesPlaceholder := "$" + strconv.Itoa(len(args)-2)
+		codePlaceholder := "$" + strconv.Itoa(len(args)-1)
+		numbersPlaceholder := "$" + strconv.Itoa(len(args))
+
+		valueConditions := []string{
+			"search_normalize(pav.value_text) IN (SELECT search_normalize(x) FROM UNNEST(" + valuesPlaceholder + "::text[]) x)",
+			"pav.value_number = ANY(" + numbersPlaceholder + "::numeric[])",
+		}
+		for _, r := range ranges {
+			condition := "pav.value_number IS NOT NULL"
+			if r.Min != nil {
+				args = append(args, *r.Min)
+				condition += " AND pav.value_number >= $" + strconv.Itoa(len(args))
+			}
+			if r.Max != nil {
+				args = append(args, *r.Max)
+				condition += " AND pav.value_number <= $" + strconv.Itoa(len(args))
+			}
+			valueConditions = append(valueConditions, "("+condition+")")
+		}
+
+		where += ` AND (EXISTS (
+			SELECT 1 FROM product_variants v
+			JOIN product_variant_options vo ON vo.variant_id = v.id
+			JOIN product_option_values ov ON ov.id = vo.option_value_id
+			JOIN product_options o ON o.id = ov.option_id
+			WHERE v.product_id = p.id AND v.is_active = true
+			  AND search_normalize(o.name) = search_normalize(` + namePlaceholder + `)
+			  AND search_normalize(ov.value) IN (SELECT search_normalize(x) FROM UNNEST(` + valuesPlaceholder + `::text[]) x))
+		  OR EXISTS (
+			SELECT 1 FROM product_attribute_values pav
+			JOIN attribute_definitions ad ON ad.id = pav.attribute_id AND ad.is_filterable
+			WHERE pav.product_id = p.id
+			  AND (ad.code = ` + codePlaceholder + ` OR search_normalize(ad.name) = search_normalize(` + namePlaceholder + `))
+			  AND (` + strings.Join(valueConditions, " OR ") + `)))`
+	}
+
+	// Stok filtresi listelemedeki stock_status ile aynı ifadeden hesaplanır (hold'lar düşülmüş)
+	switch f.StockFilter {
+	case "IN_STOCK", "LOW_STOCK", "OUT_OF_STOCK":
+		where += " AND " + stockStatusSQL + " = '" + f.StockFilter + "'"
+	}
+	return where, args, rankSQL, searchPlaceholder
+}
+
+// countProducts filtrelere uyan ürün sayısı
+func countProducts(f productFilters) (int, error) {
+	where, args, _, _ := f.where()
+
+	// DÜZELTME: DISTINCT COUNT kullan duplicate'ları önlemek için
+	query := `
+		SELECT COUNT(DISTINCT p.id)
+	` + productFromSQL + where
+
+	var count int
+	err := database.DB.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+// loadProductPage filtrelere uyan ürünlerin bir sayfasını stok bilgisiyle döner.
+// Sayfa dolduysa bir sonraki sayfanın cursor'ı da döner. Tam metin aramasında
+// eşleşmelere vurgulu alıntılar eklenir.
+func loadProductPage(f productFilters, page productPage) ([]models.ProductWithStock, string, error) {
+	where, args, rankSQL, searchPlaceholder := f.where()
+	highlight := searchPlaceholder != "" && !f.Fuzzy
+
+	sortExpr, desc := productSortSQL(page.Sort, rankSQL)
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	// EKLEME: Keyset sayfalama; son görülen (sıralama anahtarı, id) çiftinden sonrası
+	if page.After != nil {
+		args = append(args, page.After.Key, page.After.ID)
+		keyPlaceholder := "$" + strconv.Itoa(len(args)-1)
+		idPlaceholder := "$" + strconv.Itoa(len(args))
+		where += " AND (" + sortExpr + " " + comparison + " " + keyPlaceholder +
+			" OR (" + sortExpr + " = " + keyPlaceholder + " AND p.id " + comparison + " " + idPlaceholder + "))"
+	}
+
+	// DÜZELTME: COALESCE sorununu çözmek için query'yi basitleştir
+	query := `
+		SELECT
+			p.id,
+			COALESCE(p.title, '') AS title,
+			COALESCE(p.description, '') AS description,
+			COALESCE(p.price, 0) AS price,
+			COALESCE(p.image, '') AS image,
+			COALESCE(p.category, '') AS category,
+			p.category_id,
+			COALESCE(p.sku, '') AS sku,
+			COALESCE(p.rating, 0) AS rating,
+			COALESCE(p.rating_count, 0) AS rating_count,
+			p.is_active, p.created_at, p.updated_at, p.deleted_at,
+			p.compare_at_price, p.sale_price, p.sale_starts_at, p.sale_ends_at,
+			p.slug, ` + productPriceColumnsSQL + `,
+			CASE WHEN i.id IS NULL THEN 0 ELSE i.id END as inv_id,
+			CASE WHEN i.quantity IS NULL THEN 0 ELSE i.quantity END as quantity,
+			CASE WHEN i.reserved_quantity IS NULL THEN 0 ELSE i.reserved_quantity END as reserved_quantity,
+			CASE WHEN i.min_stock_level IS NULL THEN 0 ELSE i.min_stock_level END as min_stock_level,
+			CASE WHEN i.max_stock_level IS NULL THEN 0 ELSE i.max_stock_level END as max_stock_level,
+			i.cost_price,
+			CASE WHEN i.updated_at IS NULL THEN p.updated_at ELSE i.updated_at END as inv_updated_at,
+			` + availableStockSQL + ` as available_stock,
+			` + stockStatusSQL + ` as stock_status,
+			` + rankSQL + ` as search_rank,
+			(` + sortExpr + `)::text as sort_key,
+			` + sortExpr + ` as sort_value
+	` + productFromSQL + where
+
+	// id ikincil anahtardır; eşit değerlerde sıranın (ve cursor'ın) kararlı olmasını sağlar
+	query += " ORDER BY " + sortExpr + " " + direction + ", p.id " + direction
+	// Bir fazla satır istenir; gelirse sonraki sayfa vardır
+	args = append(args, page.Limit+1)
+	query += " LIMIT $" + strconv.Itoa(len(args))
+	if page.After == nil {
+		args = append(args, page.Offset)
+		query += " OFFSET $" + strconv.Itoa(len(args))
+	}
+
+	// Vurgulu alıntılar yalnızca sayfadaki satırlar için üretilir (ts_headline pahalıdır)
+	if highlight {
+		query = `SELECT r.*, ` +
+			searchHeadlineSQL("r.title", searchPlaceholder, true) + ` AS title_highlight, ` +
+			searchHeadlineSQL("r.description", searchPlaceholder, false) + ` AS description_highlight
+		FROM (` + query + `) r
+		ORDER BY r.sort_value ` + direction + `, r.id ` + direction
+	}
+
+	// DÜZELTME: limit her zaman var, bu yüzden args asla boş olamaz
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var products []models.ProductWithStock
+	var sortKeys []string
+	for rows.Next() {
+		var product models.ProductWithStock
+		var inventory models.Inventory
+		// DÜZELTME: Null değerler için proper handling
+		var invID, invQuantity, invReserved, invMin, invMax int
+		var invCost *float64
+		var invUpdatedAt time.Time
+
+		var rank float64
+		var sortKey string
+		var sortValue interface{}
+		var titleHighlight, descriptionHighlight string
+
+		dest := []interface{}{
+			&product.ID, &product.Title, &product.Description, &product.Price,
+			&product.Image, &product.Category, &product.CategoryID, &product.SKU, &product.Rating,
+			&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+			&product.CompareAtPrice, &product.SalePrice, &product.SaleStartsAt, &product.SaleEndsAt,
+			&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
+			&invID, &invQuantity, &invReserved, &invMin, &invMax, &invCost, &invUpdatedAt,
+			&product.AvailableStock, &product.StockStatus, &rank, &sortKey, &sortValue,
+		}
+		if highlight {
+			dest = append(dest, &titleHighlight, &descriptionHighlight)
+		}
+		err := rows.Scan(dest...)
+		if err != nil {
+			continue
+		}
+
+		if searchPlaceholder != "" {
+			product.SearchRank = &rank
+		}
+		if highlight {
+			product.Highlight = &models.SearchHighlight{
+				Title:       highlightHTML(titleHighlight),
+				Description: highlightHTML(descriptionHighlight),
+			}
+		}
+
+		// Inventory bilgisi varsa ekle (id > 0 kontrolü ile)
+		if invID > 0 {
+			inventory.ID = invID
+			inventory.ProductID = product.ID
+			inventory.Quantity = invQuantity
+			inventory.ReservedQuantity = invReserved
+			inventory.MinStockLevel = invMin
+			inventory.MaxStockLevel = invMax
+			if invCost != nil {
+				inventory.CostPrice = *invCost
+			} else {
+				inventory.CostPrice = 0.0 // Default value if NULL
+			}
+			inventory.UpdatedAt = invUpdatedAt
+			product.Inventory = &inventory
+		} else {
+			// Inventory yok ise nil
+			product.Inventory = nil
+		}
+
+		products = append(products, product)
+		sortKeys = append(sortKeys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(products) > page.Limit {
+		products = products[:page.Limit]
+		nextCursor = productCursor{
+			Sort:  page.Sort,
+			Fuzzy: f.Fuzzy,
+			Key:   sortKeys[page.Limit-1],
+			ID:    products[len(products)-1].ID,
+		}.encode()
+	}
+	return products, nextCursor, nil
+}
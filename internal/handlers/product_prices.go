@@ -0,0 +1,211 @@
+// ========================================
+// internal/handlers/product_prices.go - İNDİRİMLİ FİYAT, FİYAT GEÇMİŞİ VE MALİYET KONTROLÜ
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Fiyat hesaplamaları; products tablosunun "p" alias'ı ile kullanıldığı sorgularda ortaktır.
+// effectivePriceSQL şu an geçerli satış fiyatı, originalPriceSQL ise üstü çizili gösterilecek
+// fiyattır (normal fiyat veya compare_at_price'ın büyüğü; geçerli fiyattan yüksek değilse NULL).
+const (
+	saleActiveSQL = `(p.sale_price IS NOT NULL
+				AND (p.sale_starts_at IS NULL OR p.sale_starts_at <= NOW())
+				AND (p.sale_ends_at IS NULL OR p.sale_ends_at > NOW()))`
+	effectivePriceSQL = `(CASE WHEN ` + saleActiveSQL + ` THEN p.sale_price ELSE COALESCE(p.price, 0) END)`
+	originalPriceSQL  = `(CASE WHEN GREATEST(COALESCE(p.compare_at_price, 0), COALESCE(p.price, 0)) > ` + effectivePriceSQL + `
+				THEN GREATEST(COALESCE(p.compare_at_price, 0), COALESCE(p.price, 0)) END)`
+)
+
+// productPriceColumnsSQL ürün listeleyen sorgularda geçerli ve üstü çizili fiyat sütunları
+// (models.Product EffectivePrice, OriginalPrice)
+const productPriceColumnsSQL = effectivePriceSQL + ` AS effective_price, ` + originalPriceSQL + ` AS original_price`
+
+// Fiyat geçmişi kaynakları
+const (
+	priceSourceCreate = "create"
+	priceSourceUpdate = "update"
+	priceSourceImport = "import"
+)
+
+var (
+	errSalePriceNotLower = errors.New("İndirimli fiyat normal fiyattan düşük olmalı")
+	errSaleWindow        = errors.New("sale_ends_at, sale_starts_at'ten sonra olmalı")
+)
+
+// belowCostError fiyatın maliyetin altına düştüğünü belirtir; allow_below_cost ile onaylanabilir
+type belowCostError struct {
+	Field     string
+	Price     float64
+	CostPrice float64
+}
+
+func (e *belowCostError) Error() string {
+	return fmt.Sprintf("%s (%.2f) maliyet fiyatının (%.2f) altında; onaylamak için allow_below_cost kullanın", e.Field, e.Price, e.CostPrice)
+}
+
+// respondPriceError fiyat doğrulama hatalarını döner: maliyet altı 409, geçersiz indirim 400, diğerleri 500
+func respondPriceError(c *gin.Context, err error) {
+	var belowCost *belowCostError
+	switch {
+	case errors.As(err, &belowCost):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      belowCost.Error(),
+			"field":      belowCost.Field,
+			"price":      belowCost.Price,
+			"cost_price": belowCost.CostPrice,
+		})
+	case errors.Is(err, errSalePriceNotLower), errors.Is(err, errSaleWindow):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat kaydedilemedi: " + err.Error()})
+	}
+}
+
+// validateSalePrice indirimli fiyatın normal fiyattan düşük ve zaman aralığının geçerli olduğunu kontrol eder
+func validateSalePrice(price float64, salePrice *float64, startsAt, endsAt *time.Time) error {
+	if salePrice != nil && *salePrice >= price {
+		return errSalePriceNotLower
+	}
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return errSaleWindow
+	}
+	return nil
+}
+
+// checkPriceAboveCost ürünün kayıtlı normal ve indirimli fiyatını maliyetle karşılaştırır.
+// Zamanlanmış indirim henüz başlamamış olsa da kontrol edilir; başladığında maliyetin altında satılmasın.
+// Maliyeti girilmemiş (0) ürünlerde kontrol yapılmaz.
+func checkPriceAboveCost(q queryRower, productID int) error {
+	var price, cost float64
+	var salePrice *float64
+	err := q.QueryRow(`
+		SELECT COALESCE(p.price, 0), p.sale_price, COALESCE(i.cost_price, 0)
+		FROM products p
+		LEFT JOIN inventory i ON i.product_id = p.id
+		WHERE p.id = $1
+	`, productID).Scan(&price, &salePrice, &cost)
+	if err != nil {
+		return err
+	}
+	if cost <= 0 {
+		return nil
+	}
+	if price < cost {
+		return &belowCostError{Field: "price", Price: price, CostPrice: cost}
+	}
+	if salePrice != nil && *salePrice < cost {
+		return &belowCostError{Field: "sale_price", Price: *salePrice, CostPrice: cost}
+	}
+	return nil
+}
+
+// guardPriceAboveCost maliyet kontrolünü uygular. Fiyat maliyetin altındaysa allow false iken
+// belowCostError döner; allow true ise kabul edilir ve geçmişe işaretlenmesi için true döner.
+func guardPriceAboveCost(q queryRower, productID int, allow bool) (bool, error) {
+	err := checkPriceAboveCost(q, productID)
+	var belowCost *belowCostError
+	if errors.As(err, &belowCost) && allow {
+		return true, nil
+	}
+	return false, err
+}
+
+// positivePriceOrNil 0 veya negatif fiyatı "yok" (NULL) olarak yorumlar
+func positivePriceOrNil(v *float64) *float64 {
+	if v == nil || *v <= 0 {
+		return nil
+	}
+	return v
+}
+
+// recordPriceChange ürünün güncel fiyat alanlarını geçmişe yazar. Son kayıtla aynıysa
+// (fiyat değişmemişse) yeni kayıt eklenmez; bu yüzden her güncellemeden sonra çağrılabilir.
+func recordPriceChange(tx *sql.Tx, productID int, source, changedBy string, belowCostOverride bool) error {
+	var by *string
+	if changedBy != "" {
+		by = &changedBy
+	}
+	_, err := tx.Exec(`
+		INSERT INTO product_price_history
+			(product_id, price, compare_at_price, sale_price, sale_starts_at, sale_ends_at, source, changed_by, below_cost_override)
+		SELECT p.id, COALESCE(p.price, 0), p.compare_at_price, p.sale_price, p.sale_starts_at, p.sale_ends_at, $2, $3, $4
+		FROM products p
+		WHERE p.id = $1
+		  AND NOT EXISTS (
+		      SELECT 1 FROM (
+		          SELECT * FROM product_price_history h
+		          WHERE h.product_id = p.id
+		          ORDER BY h.changed_at DESC, h.id DESC
+		          LIMIT 1
+		      ) latest
+		      WHERE latest.price = COALESCE(p.price, 0)
+		        AND latest.compare_at_price IS NOT DISTINCT FROM p.compare_at_price
+		        AND latest.sale_price IS NOT DISTINCT FROM p.sale_price
+		        AND latest.sale_starts_at IS NOT DISTINCT FROM p.sale_starts_at
+		        AND latest.sale_ends_at IS NOT DISTINCT FROM p.sale_ends_at
+		  )
+	`, productID, source, by, belowCostOverride)
+	return err
+}
+
+// GetProductPriceHistory ürünün fiyat değişikliklerini yeniden eskiye listeler
+func (h *ProductHandler) GetProductPriceHistory(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+	limit, ok := boundedIntQuery(c, "limit", 50, 500)
+	if !ok {
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, product_id, price, compare_at_price, sale_price, sale_starts_at, sale_ends_at,
+		       source, changed_by, below_cost_override, changed_at
+		FROM product_price_history
+		WHERE product_id = $1
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $2
+	`, productID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat geçmişi alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	history := []models.ProductPriceChange{}
+	for rows.Next() {
+		var change models.ProductPriceChange
+		if err := rows.Scan(
+			&change.ID, &change.ProductID, &change.Price, &change.CompareAtPrice, &change.SalePrice,
+			&change.SaleStartsAt, &change.SaleEndsAt, &change.Source, &change.ChangedBy,
+			&change.BelowCostOverride, &change.ChangedAt,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat geçmişi okunamadı: " + err.Error()})
+			return
+		}
+		history = append(history, change)
+	}
+	if len(history) == 0 {
+		var exists bool
+		if err := database.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists); err == nil && !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product_id": productID, "history": history})
+}
@@ -0,0 +1,242 @@
+// ========================================
+// internal/handlers/product_related.go - İLGİLİ ÜRÜNLER VE ÖNERİ YÖNETİMİ
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRelatedProducts = 8
+	maxRelatedProducts     = 50
+)
+
+// GetRelatedProducts ürünle ilgili ürünleri döner: önce yöneticinin sabitledikleri (position
+// sırasıyla), ardından RecommendationJob'ın hesapladığı skora göre diğerleri. Hariç tutulan,
+// pasif ve arşivlenmiş ürünler listelenmez.
+func (h *ProductHandler) GetRelatedProducts(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	limit := defaultRelatedProducts
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz limit"})
+			return
+		}
+		if limit > maxRelatedProducts {
+			limit = maxRelatedProducts
+		}
+	}
+
+	var exists bool
+	if err := database.DB.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND is_active = true AND deleted_at IS NULL)", productID,
+	).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün alınamadı: " + err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	}
+
+	query := `
+		SELECT
+			r.related_id, r.score, r.reason, r.co_purchase_count, r.pinned,
+			p.id,
+			COALESCE(p.title, '') AS title,
+			COALESCE(p.description, '') AS description,
+			COALESCE(p.price, 0) AS price,
+			COALESCE(p.image, '') AS image,
+			COALESCE(p.category, '') AS category,
+			p.category_id,
+			COALESCE(p.sku, '') AS sku,
+			COALESCE(p.rating, 0) AS rating,
+			COALESCE(p.rating_count, 0) AS rating_count,
+			p.is_active, p.created_at, p.updated_at,
+			p.slug, ` + productPriceColumnsSQL + `,
+			` + availableStockSQL + ` as available_stock,
+			` + stockStatusSQL + ` as stock_status
+		FROM (
+			SELECT o.related_product_id AS related_id, COALESCE(pr.score, 0) AS score, 'pinned' AS reason,
+			       COALESCE(pr.co_purchase_count, 0) AS co_purchase_count, true AS pinned, o.position
+			FROM product_recommendation_overrides o
+			LEFT JOIN product_recommendations pr
+			       ON pr.product_id = o.product_id AND pr.related_product_id = o.related_product_id
+			WHERE o.product_id = $1 AND o.action = 'pin'
+			UNION ALL
+			SELECT pr.related_product_id, pr.score, pr.reason, pr.co_purchase_count, false, 0
+			FROM product_recommendations pr
+			WHERE pr.product_id = $1
+			  AND NOT EXISTS (
+			      SELECT 1 FROM product_recommendation_overrides o
+			      WHERE o.product_id = pr.product_id AND o.related_product_id = pr.related_product_id
+			  )
+		) r
+		JOIN products p ON p.id = r.related_id
+		LEFT JOIN inventory i ON p.id = i.product_id
+		WHERE p.is_active = true AND p.deleted_at IS NULL
+		ORDER BY r.pinned DESC, r.position, r.score DESC, p.id
+		LIMIT $2
+	`
+
+	rows, err := database.DB.Query(query, productID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İlgili ürünler alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	related := []models.RelatedProduct{}
+	for rows.Next() {
+		var item models.RelatedProduct
+		var product models.ProductWithStock
+		if err := rows.Scan(
+			&item.ProductID, &item.Score, &item.Reason, &item.CoPurchaseCount, &item.Pinned,
+			&product.ID, &product.Title, &product.Description, &product.Price,
+			&product.Image, &product.Category, &product.CategoryID, &product.SKU, &product.Rating,
+			&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
+			&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
+			&product.AvailableStock, &product.StockStatus,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "İlgili ürünler okunamadı: " + err.Error()})
+			return
+		}
+		item.Product = &product
+		related = append(related, item)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İlgili ürünler okunamadı: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product_id": productID, "related": related})
+}
+
+// GetRecommendationOverrides ürün için sabitlenen ve hariç tutulan önerileri listeler
+func (h *ProductHandler) GetRecommendationOverrides(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT o.product_id, o.related_product_id, COALESCE(p.title, ''), o.action, o.position, o.created_at, o.updated_at
+		FROM product_recommendation_overrides o
+		JOIN products p ON p.id = o.related_product_id
+		WHERE o.product_id = $1
+		ORDER BY o.action DESC, o.position, o.related_product_id
+	`, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Öneri ayarları alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	overrides := []models.ProductRecommendationOverride{}
+	for rows.Next() {
+		var override models.ProductRecommendationOverride
+		if err := rows.Scan(
+			&override.ProductID, &override.RelatedProductID, &override.RelatedTitle, &override.Action,
+			&override.Position, &override.CreatedAt, &override.UpdatedAt,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Öneri ayarları okunamadı: " + err.Error()})
+			return
+		}
+		overrides = append(overrides, override)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product_id": productID, "overrides": overrides})
+}
+
+// SetRecommendationOverride bir ürünü önerilerde sabitler (pin) veya hariç tutar (exclude).
+// Karar job'ın sonraki hesaplamalarından etkilenmez; okuma sırasında uygulanır.
+func (h *ProductHandler) SetRecommendationOverride(c *gin.Context) {
+	productID, relatedID, ok := recommendationOverrideIDs(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetRecommendationOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var override models.ProductRecommendationOverride
+	err := database.DB.QueryRow(`
+		INSERT INTO product_recommendation_overrides (product_id, related_product_id, action, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (product_id, related_product_id) DO UPDATE SET
+		  action = EXCLUDED.action,
+		  position = EXCLUDED.position,
+		  updated_at = NOW()
+		RETURNING product_id, related_product_id, action, position, created_at, updated_at
+	`, productID, relatedID, req.Action, req.Position).Scan(
+		&override.ProductID, &override.RelatedProductID, &override.Action,
+		&override.Position, &override.CreatedAt, &override.UpdatedAt,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Öneri ayarı kaydedilemedi: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"override": override})
+}
+
+// DeleteRecommendationOverride sabitleme / hariç tutma kararını kaldırır; ürün yeniden
+// hesaplanan skoruna göre listelenir
+func (h *ProductHandler) DeleteRecommendationOverride(c *gin.Context) {
+	productID, relatedID, ok := recommendationOverrideIDs(c)
+	if !ok {
+		return
+	}
+
+	result, err := database.DB.Exec(
+		"DELETE FROM product_recommendation_overrides WHERE product_id = $1 AND related_product_id = $2",
+		productID, relatedID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Öneri ayarı silinemedi: " + err.Error()})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Öneri ayarı bulunamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Öneri ayarı kaldırıldı"})
+}
+
+func recommendationOverrideIDs(c *gin.Context) (int, int, bool) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return 0, 0, false
+	}
+	relatedID, err := strconv.Atoi(c.Param("relatedId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz related product ID"})
+		return 0, 0, false
+	}
+	if productID == relatedID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ürün kendisiyle ilişkilendirilemez"})
+		return 0, 0, false
+	}
+	return productID, relatedID, true
+}
@@ -0,0 +1,68 @@
+// ========================================
+// internal/handlers/product_search.go - TAM METİN ÜRÜN ARAMASI
+// ========================================
+package handlers
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// searchConfig 011 migration'ında oluşturulan (unaccent + Türkçe kök) arama konfigürasyonu
+const searchConfig = "turkish_unaccent"
+
+// ts_headline vurgu işaretleri. Metindeki HTML'i güvenle kaçırabilmek için önce
+// metinde geçmesi beklenmeyen özel kullanım alanı karakterleri kullanılır,
+// kaçırma sonrası <mark> etiketlerine çevrilir (bkz. highlightHTML).
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// searchTSQuerySQL kullanıcının yazdığı aramayı (tırnak, -hariç, or desteğiyle) tsquery'ye çevirir
+func searchTSQuerySQL(placeholder string) string {
+	return "websearch_to_tsquery('" + searchConfig + "', " + placeholder + ")"
+}
+
+// searchRankSQL eşleşme skorunu hesaplar; normalizasyon 32 skoru 0-1 aralığına çeker.
+// float8'e çevrilir ki cursor'daki metin değeri karşılaştırmada birebir geri dönebilsin.
+func searchRankSQL(placeholder string) string {
+	return "ts_rank_cd(p.search_vector, " + searchTSQuerySQL(placeholder) + ", 32)::float8"
+}
+
+// searchHeadlineSQL column içinde eşleşen kelimeleri işaretleyen kısa bir alıntı üretir.
+// wholeText true ise (başlık gibi kısa alanlar) metnin tamamı döner.
+func searchHeadlineSQL(column, placeholder string, wholeText bool) string {
+	options := "StartSel=" + highlightStart + ", StopSel=" + highlightStop
+	if wholeText {
+		options += ", HighlightAll=true"
+	} else {
+		options += `, MaxWords=35, MinWords=15, ShortWord=2, MaxFragments=2, FragmentDelimiter=" … "`
+	}
+	return "ts_headline('" + searchConfig + "', COALESCE(" + column + ", ''), " +
+		searchTSQuerySQL(placeholder) + ", '" + strings.ReplaceAll(options, "'", "''") + "')"
+}
+
+// appendSearchFilter arama metnini tam metin indeksi üzerinden filtre olarak ekler;
+// SKU ile birebir (büyük/küçük harf duyarsız) eşleşmeler de sonuca dahildir.
+// Arama parametresinin yer tutucusunu döner (arama yoksa boş).
+func appendSearchFilter(query string, args []interface{}, search string) (string, []interface{}, string) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return query, args, ""
+	}
+	args = append(args, search)
+	placeholder := "$" + strconv.Itoa(len(args))
+	query += " AND (p.search_vector @@ " + searchTSQuerySQL(placeholder) +
+		" OR LOWER(COALESCE(p.sku, '')) = LOWER(" + placeholder + "))"
+	return query, args, placeholder
+}
+
+// highlightHTML ts_headline çıktısını HTML olarak güvenli hale getirir ve
+// eşleşmeleri <mark> etiketleriyle sarar
+func highlightHTML(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	return strings.ReplaceAll(s, highlightStop, "</mark>")
+}
@@ -0,0 +1,170 @@
+// ========================================
+// internal/handlers/product_slug.go - ÜRÜN SLUG'LARI VE ESKİ SLUG YÖNLENDİRMELERİ
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/database"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidProductSlug = errors.New("Geçerli bir slug üretilemedi")
+	errProductSlugTaken   = errors.New("Bu slug başka bir üründe kullanılıyor")
+)
+
+// resolveProductSlug istekteki slug'ı normalize eder; boşsa başlıktan benzersiz bir slug üretir.
+// Açıkça verilen slug başka bir ürünün güncel slug'ıysa errProductSlugTaken döner; başka bir
+// ürünün eski (yönlendirilen) slug'ı ise yeni ürün tarafından devralınabilir.
+func resolveProductSlug(q queryer, requested, title string, excludeID int) (string, error) {
+	if slug := slugify(requested); slug != "" {
+		rows, err := q.Query("SELECT 1 FROM products WHERE slug = $1 AND id <> $2", slug, excludeID)
+		if err != nil {
+			return "", err
+		}
+		taken := rows.Next()
+		rows.Close()
+		if taken {
+			return "", errProductSlugTaken
+		}
+		return slug, nil
+	}
+	if strings.TrimSpace(requested) != "" {
+		return "", errInvalidProductSlug
+	}
+
+	return uniqueProductSlug(q, slugOrDefault(title), excludeID)
+}
+
+// slugOrDefault başlığın slug'ı; yalnızca sembollerden oluşan başlıklar için "urun"
+func slugOrDefault(title string) string {
+	if slug := slugify(title); slug != "" {
+		return slug
+	}
+	return "urun"
+}
+
+// uniqueProductSlug base slug'ı ürünlerde ve diğer ürünlerin eski slug'larında çakışmıyorsa
+// olduğu gibi, çakışıyorsa sonuna -2, -3... ekleyerek döner
+func uniqueProductSlug(q queryer, base string, excludeID int) (string, error) {
+	rows, err := q.Query(`
+		SELECT slug FROM products WHERE (slug = $1 OR slug LIKE $1 || '-%') AND id <> $2
+		UNION
+		SELECT slug FROM product_slug_redirects WHERE (slug = $1 OR slug LIKE $1 || '-%') AND product_id <> $2
+	`, base, excludeID)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", err
+		}
+		taken[slug] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	if !taken[base] {
+		return base, nil
+	}
+	suffixes := make([]int, 0, len(taken))
+	for slug := range taken {
+		if n, err := strconv.Atoi(strings.TrimPrefix(slug, base+"-")); err == nil {
+			suffixes = append(suffixes, n)
+		}
+	}
+	sort.Ints(suffixes)
+	next := 2
+	for _, n := range suffixes {
+		if n == next {
+			next++
+		}
+	}
+	return base + "-" + strconv.Itoa(next), nil
+}
+
+// saveProductSlugRedirect slug değişikliğinden önce çağrılır: eski slug yeni slug'a yönlendirilir,
+// yeni slug daha önce bir yönlendirme olarak kullanılıyorsa o kayıt kaldırılır
+func saveProductSlugRedirect(tx *sql.Tx, productID int, oldSlug, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+	if _, err := tx.Exec("DELETE FROM product_slug_redirects WHERE slug = $1", newSlug); err != nil {
+		return err
+	}
+	if oldSlug == "" {
+		return nil
+	}
+	_, err := tx.Exec(`
+		INSERT INTO product_slug_redirects (slug, product_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (slug) DO UPDATE SET product_id = EXCLUDED.product_id, created_at = NOW()
+	`, oldSlug, productID)
+	return err
+}
+
+func respondProductSlugError(c *gin.Context, err error, slug string) {
+	switch {
+	case errors.Is(err, errProductSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "slug": slugify(slug)})
+	case errors.Is(err, errInvalidProductSlug):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case isUniqueViolation(err):
+		c.JSON(http.StatusConflict, gin.H{"error": errProductSlugTaken.Error(), "slug": slugify(slug)})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Slug üretilemedi: " + err.Error()})
+	}
+}
+
+// GetProductBySlug ürünü slug ile döner (GetProduct ile aynı yanıt). Eski bir slug istenirse
+// ürünün güncel slug'ına 301 ile yönlendirilir.
+func (h *ProductHandler) GetProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	var productID int
+	err := database.DB.QueryRow(
+		"SELECT id FROM products WHERE slug = $1 AND is_active = true AND deleted_at IS NULL", slug,
+	).Scan(&productID)
+	if err == nil {
+		h.respondProduct(c, productID)
+		return
+	}
+	if err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün alınamadı: " + err.Error()})
+		return
+	}
+
+	var current string
+	err = database.DB.QueryRow(`
+		SELECT p.slug FROM product_slug_redirects r
+		JOIN products p ON p.id = r.product_id
+		WHERE r.slug = $1 AND p.is_active = true AND p.deleted_at IS NULL
+	`, slug).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün alınamadı: " + err.Error()})
+		return
+	}
+
+	location := strings.TrimSuffix(c.Request.URL.Path, c.Param("slug")) + url.PathEscape(current)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+}
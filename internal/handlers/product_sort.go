@@ -0,0 +1,108 @@
+// ========================================
+// internal/handlers/product_sort.go - ÜRÜN SIRALAMA VE CURSOR (KEYSET) SAYFALAMA
+// ========================================
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const (
+	sortRelevance = "relevance"
+	sortNewest    = "newest"
+	sortPriceAsc  = "price_asc"
+	sortPriceDesc = "price_desc"
+	sortRating    = "rating"
+	sortName      = "name"
+)
+
+// productSorts izin verilen sıralamalar; sort parametresi yalnızca bu anahtarlardan biri olabilir.
+// İfadeler NULL dönmeyecek şekilde yazılır, aksi halde keyset karşılaştırması satır kaçırır.
+// Fiyat sıralaması o an geçerli (indirimli) fiyata göredir.
+var productSorts = map[string]struct {
+	expr string
+	desc bool
+}{
+	sortNewest:    {expr: "p.created_at", desc: true},
+	sortPriceAsc:  {expr: effectivePriceSQL},
+	sortPriceDesc: {expr: effectivePriceSQL, desc: true},
+	sortRating:    {expr: "COALESCE(p.rating, 0)", desc: true},
+	sortName:      {expr: "LOWER(COALESCE(p.title, ''))"},
+	// relevance ifadesi aramaya göre (rankSQL) belirlenir
+	sortRelevance: {desc: true},
+}
+
+var errInvalidSort = errors.New("Geçersiz sort değeri (relevance, newest, price_asc, price_desc, rating, name)")
+var errInvalidCursor = errors.New("Geçersiz cursor")
+
+// parseProductSort sort parametresini doğrular. Boşsa arama varken alaka, yokken en yeni
+// sıralaması kullanılır; arama olmadan relevance istenirse en yeniye düşülür.
+func parseProductSort(raw string, searching bool) (string, error) {
+	sort := strings.ToLower(strings.TrimSpace(raw))
+	switch sort {
+	case "":
+		sort = sortNewest
+		if searching {
+			sort = sortRelevance
+		}
+	case "price":
+		sort = sortPriceAsc
+	}
+	if _, ok := productSorts[sort]; !ok {
+		return "", errInvalidSort
+	}
+	if sort == sortRelevance && !searching {
+		sort = sortNewest
+	}
+	return sort, nil
+}
+
+// productSortSQL sıralama ifadesini ve yönünü döner
+func productSortSQL(sort, rankSQL string) (expr string, desc bool) {
+	s := productSorts[sort]
+	if sort == sortRelevance {
+		return rankSQL, true
+	}
+	return s.expr, s.desc
+}
+
+// productCursor son görülen satırın sıralama anahtarı ve id'si. Key veritabanının
+// ::text çıktısıdır; aynı ifadeye parametre olarak geri verildiğinde tipi Postgres
+// tarafından çıkarılır, böylece zaman damgası ve sayı hassasiyeti kaybolmaz.
+type productCursor struct {
+	Sort  string `json:"s"`
+	Fuzzy bool   `json:"f,omitempty"`
+	Key   string `json:"k"`
+	ID    int    `json:"id"`
+}
+
+func (pc productCursor) encode() string {
+	data, _ := json.Marshal(pc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeProductCursor(raw string) (*productCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var pc productCursor
+	if err := json.Unmarshal(data, &pc); err != nil || pc.ID <= 0 {
+		return nil, errInvalidCursor
+	}
+	if _, ok := productSorts[pc.Sort]; !ok {
+		return nil, errInvalidCursor
+	}
+	return &pc, nil
+}
+
+// productPage sayfalama isteği: After doluysa keyset (cursor), değilse OFFSET kullanılır
+type productPage struct {
+	Sort   string
+	Limit  int
+	Offset int
+	After  *productCursor
+}
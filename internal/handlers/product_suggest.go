@@ -0,0 +1,219 @@
+// ========================================
+// internal/handlers/product_suggest.go - ARAMA ÖNERİLERİ VE BULANIK (FUZZY) ARAMA
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/database"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	suggestMinQueryLength = 2
+	suggestDefaultLimit   = 8
+	suggestMaxLimit       = 20
+	// didYouMeanThreshold bir kelimenin düzeltme olarak önerilmesi için gereken en düşük trigram benzerliği
+	didYouMeanThreshold = 0.3
+)
+
+// Trigram operatörleri (012 migration, pg_trgm) varsayılan eşikleri kullanır:
+// "%" tüm metin benzerliği >= 0.3, "<%" metindeki en benzer kelime grubu >= 0.6.
+// Her ikisi de search_normalize üzerindeki GIN indekslerini kullanabilir.
+
+// appendFuzzySearchFilter aramayı başlık, SKU ve kategori adları üzerinde trigram benzerliğiyle filtreler
+func appendFuzzySearchFilter(query string, args []interface{}, search string) (string, []interface{}, string) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return query, args, ""
+	}
+	args = append(args, search)
+	placeholder := "$" + strconv.Itoa(len(args))
+	normalized := "search_normalize(" + placeholder + ")"
+	query += " AND (" + normalized + " <% search_normalize(p.title)" +
+		" OR search_normalize(COALESCE(p.sku, '')) % " + normalized +
+		" OR p.category_id IN (SELECT id FROM categories WHERE " + normalized + " <% search_normalize(name)))"
+	return query, args, placeholder
+}
+
+// fuzzyRankSQL başlık ve SKU benzerliklerinin büyüğünü skor olarak kullanır
+func fuzzyRankSQL(placeholder string) string {
+	normalized := "search_normalize(" + placeholder + ")"
+	return "GREATEST(word_similarity(" + normalized + ", search_normalize(p.title)), " +
+		"similarity(search_normalize(COALESCE(p.sku, '')), " + normalized + "))::float8"
+}
+
+// escapeLike LIKE kalıbındaki özel karakterleri kaçırır
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SuggestProducts arama kutusu için hızlı öneriler: önce başlangıç (prefix) eşleşmeleri,
+// ardından yazım hatalarına toleranslı benzer ürünler, kategoriler ve SKU'lar
+func (h *ProductHandler) SuggestProducts(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(suggestDefaultLimit)))
+	if limit <= 0 || limit > suggestMaxLimit {
+		limit = suggestDefaultLimit
+	}
+
+	if len([]rune(q)) < suggestMinQueryLength {
+		c.JSON(http.StatusOK, gin.H{"query": q, "products": []gin.H{}, "categories": []gin.H{}})
+		return
+	}
+
+	// $1: ham sorgu (benzerlik), $2: LIKE için kaçırılmış sorgu (prefix)
+	productRows, err := database.DB.Query(`
+		WITH q AS (SELECT search_normalize($1) AS n, search_normalize($2) AS prefix)
+		SELECT p.id, p.title, COALESCE(p.sku, ''), COALESCE(p.image, ''),
+		       (search_normalize(p.title) LIKE q.prefix || '%' OR search_normalize(p.title) LIKE '% ' || q.prefix || '%') AS prefix_match,
+		       search_normalize(COALESCE(p.sku, '')) LIKE q.prefix || '%' AS sku_match,
+		       GREATEST(word_similarity(q.n, search_normalize(p.title)),
+		                similarity(search_normalize(COALESCE(p.sku, '')), q.n)) AS score
+		FROM products p, q
+		WHERE p.is_active = true AND p.deleted_at IS NULL
+		  AND (search_normalize(p.title) LIKE q.prefix || '%'
+		       OR search_normalize(p.title) LIKE '% ' || q.prefix || '%'
+		       OR search_normalize(COALESCE(p.sku, '')) LIKE q.prefix || '%'
+		       OR q.n <% search_normalize(p.title))
+		ORDER BY prefix_match DESC, sku_match DESC, score DESC, p.rating_count DESC
+		LIMIT $3
+	`, q, escapeLike(q), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Öneriler alınamadı: " + err.Error()})
+		return
+	}
+	defer productRows.Close()
+
+	products := []gin.H{}
+	for productRows.Next() {
+		var id int
+		var title, sku, image string
+		var prefixMatch, skuMatch bool
+		var score float64
+		if err := productRows.Scan(&id, &title, &sku, &image, &prefixMatch, &skuMatch, &score); err != nil {
+			continue
+		}
+		matchType := "fuzzy"
+		if skuMatch {
+			matchType = "sku"
+		} else if prefixMatch {
+			matchType = "prefix"
+		}
+		products = append(products, gin.H{
+			"id":         id,
+			"title":      title,
+			"sku":        sku,
+			"image":      image,
+			"match_type": matchType,
+			"score":      score,
+		})
+	}
+
+	categoryRows, err := database.DB.Query(`
+		WITH q AS (SELECT search_normalize($1) AS n, search_normalize($2) AS prefix)
+		SELECT c.id, c.name, c.slug,
+		       search_normalize(c.name) LIKE q.prefix || '%' AS prefix_match,
+		       word_similarity(q.n, search_normalize(c.name)) AS score
+		FROM categories c, q
+		WHERE c.is_active = true
+		  AND (search_normalize(c.name) LIKE q.prefix || '%'
+		       OR search_normalize(c.name) LIKE '% ' || q.prefix || '%'
+		       OR q.n <% search_normalize(c.name))
+		ORDER BY prefix_match DESC, score DESC, c.sort_order
+		LIMIT 5
+	`, q, escapeLike(q))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kategori önerileri alınamadı: " + err.Error()})
+		return
+	}
+	defer categoryRows.Close()
+
+	categories := []gin.H{}
+	for categoryRows.Next() {
+		var id int
+		var name, slug string
+		var prefixMatch bool
+		var score float64
+		if err := categoryRows.Scan(&id, &name, &slug, &prefixMatch, &score); err != nil {
+			continue
+		}
+		categories = append(categories, gin.H{"id": id, "name": name, "slug": slug, "score": score})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": q, "products": products, "categories": categories})
+}
+
+// didYouMean aramadaki her kelimeyi, bulanık aramada bulunan ürün başlıklarındaki
+// en benzer kelimeyle değiştirerek düzeltilmiş bir sorgu önerir. Değişiklik yoksa boş döner.
+func didYouMean(search string, titles []string) string {
+	var vocabulary []string
+	seen := map[string]bool{}
+	for _, title := range titles {
+		for _, word := range searchWords(title) {
+			if !seen[word] {
+				seen[word] = true
+				vocabulary = append(vocabulary, word)
+			}
+		}
+	}
+
+	words := searchWords(search)
+	changed := false
+	for i, word := range words {
+		if seen[word] {
+			continue
+		}
+		best, bestScore := "", didYouMeanThreshold
+		for _, candidate := range vocabulary {
+			if score := trigramSimilarity(word, candidate); score > bestScore {
+				best, bestScore = candidate, score
+			}
+		}
+		if best != "" {
+			words[i] = best
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
+// searchWords metni Türkçe kurallarıyla küçük harfe çevirip kelimelere ayırır
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLowerSpecial(unicode.TurkishCase, s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigramSimilarity pg_trgm similarity() ile aynı yöntemle (kelime "  " ile başlayıp " "
+// ile biten trigram kümeleri, ortak / birleşim) iki kelimenin benzerliğini hesaplar.
+// Karşılaştırma öncesi Türkçe karakterler sadeleştirilir.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + strings.ToLower(turkishSlugReplacer.Replace(word)) + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
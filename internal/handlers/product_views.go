@@ -0,0 +1,283 @@
+// ========================================
+// internal/handlers/product_views.go - ÜRÜN GÖRÜNTÜLEME TAKİBİ, SON BAKILANLAR, TREND ÜRÜNLER
+// ========================================
+package handlers
+
+import (
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionIDHeader giriş yapmamış ziyaretçinin istemci tarafında üretilen anonim oturum kimliği
+const sessionIDHeader = "X-Session-ID"
+
+const maxSessionIDLength = 128
+
+const (
+	defaultRecentlyViewed = 20
+	maxRecentlyViewed     = 100
+	defaultTrendingDays   = 7
+	maxTrendingDays       = 90
+	maxViewStatsDays      = 365
+)
+
+// productViewerKey görüntüleyeni tanımlar: giriş yapmış kullanıcı, X-Session-ID veya IP adresi
+func productViewerKey(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return "u:" + userID
+	}
+	if session := strings.TrimSpace(c.GetHeader(sessionIDHeader)); session != "" && len(session) <= maxSessionIDLength {
+		return "s:" + session
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// recordProductView görüntülemeyi kaydeder. Aynı görüntüleyen debounce süresi içinde
+// ürünü tekrar açarsa yalnızca "son bakılma" zamanı güncellenir, günlük sayaç artmaz.
+func recordProductView(productID int, viewerKey string, debounce time.Duration) error {
+	_, err := database.DB.Exec(`
+		WITH viewer AS (
+			INSERT INTO product_viewers (viewer_key, product_id, viewed_at, counted_at)
+			VALUES ($1, $2, NOW(), NOW())
+			ON CONFLICT (viewer_key, product_id) DO UPDATE SET
+			  viewed_at = NOW(),
+			  counted_at = CASE
+			      WHEN product_viewers.counted_at <= NOW() - make_interval(secs => $3::float8) THEN NOW()
+			      ELSE product_viewers.counted_at
+			  END
+			RETURNING counted_at = viewed_at AS counted
+		)
+		INSERT INTO product_view_daily (product_id, day, views)
+		SELECT $2, CURRENT_DATE, 1 FROM viewer WHERE counted
+		ON CONFLICT (product_id, day) DO UPDATE SET views = product_view_daily.views + 1
+	`, viewerKey, productID, debounce.Seconds())
+	return err
+}
+
+// boundedIntQuery pozitif tamsayı sorgu parametresini okur; verilmemişse def, max'tan büyükse max döner
+func boundedIntQuery(c *gin.Context, name string, def, max int) (int, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return def, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz " + name})
+		return 0, false
+	}
+	if value > max {
+		value = max
+	}
+	return value, true
+}
+
+// GetRecentlyViewed kullanıcının son baktığı aktif ürünleri en yeniden eskiye döner
+func (h *ProfileHandler) GetRecentlyViewed(c *gin.Context) {
+	userID := c.GetString("userID")
+	limit, ok := boundedIntQuery(c, "limit", defaultRecentlyViewed, maxRecentlyViewed)
+	if !ok {
+		return
+	}
+
+	query := `
+		SELECT
+			v.product_id, v.viewed_at,
+			p.id,
+			COALESCE(p.title, '') AS title,
+			COALESCE(p.description, '') AS description,
+			COALESCE(p.price, 0) AS price,
+			COALESCE(p.image, '') AS image,
+			COALESCE(p.category, '') AS category,
+			p.category_id,
+			COALESCE(p.sku, '') AS sku,
+			COALESCE(p.rating, 0) AS rating,
+			COALESCE(p.rating_count, 0) AS rating_count,
+			p.is_active, p.created_at, p.updated_at,
+			p.slug, ` + productPriceColumnsSQL + `,
+			` + productAvailableStockSQL("$3::uuid") + ` as available_stock,
+			` + productStockStatusSQL("$3::uuid") + ` as stock_status
+		FROM product_viewers v
+		JOIN products p ON p.id = v.product_id
+		LEFT JOIN inventory i ON p.id = i.product_id
+		WHERE v.viewer_key = $1 AND p.is_active = true AND p.deleted_at IS NULL
+		ORDER BY v.viewed_at DESC
+		LIMIT $2
+	`
+
+	rows, err := database.DB.Query(query, "u:"+userID, limit, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Son bakılan ürünler alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	items := []models.RecentlyViewedProduct{}
+	for rows.Next() {
+		var item models.RecentlyViewedProduct
+		var product models.ProductWithStock
+		if err := rows.Scan(
+			&item.ProductID, &item.ViewedAt,
+			&product.ID, &product.Title, &product.Description, &product.Price,
+			&product.Image, &product.Category, &product.CategoryID, &product.SKU, &product.Rating,
+			&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
+			&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
+			&product.AvailableStock, &product.StockStatus,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Son bakılan ürünler okunamadı: " + err.Error()})
+			return
+		}
+		item.Product = &product
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"products": items})
+}
+
+// ClearRecentlyViewed kullanıcının görüntüleme geçmişini temizler; günlük istatistikler etkilenmez
+func (h *ProfileHandler) ClearRecentlyViewed(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if _, err := database.DB.Exec("DELETE FROM product_viewers WHERE viewer_key = $1", "u:"+userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Görüntüleme geçmişi temizlenemedi: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Görüntüleme geçmişi temizlendi"})
+}
+
+// GetTrendingProducts son N günde en çok görüntülenen aktif ürünleri döner (?days=7&limit=10&category_id=3)
+func (h *ProductHandler) GetTrendingProducts(c *gin.Context) {
+	days, ok := boundedIntQuery(c, "days", defaultTrendingDays, maxTrendingDays)
+	if !ok {
+		return
+	}
+	limit, ok := boundedIntQuery(c, "limit", defaultRelatedProducts, maxRelatedProducts)
+	if !ok {
+		return
+	}
+	categoryID, err := categoryIDQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	args := []interface{}{days}
+	where := " WHERE p.is_active = true AND p.deleted_at IS NULL"
+	where, args = appendCategoryFilter(where, args, categoryID, c.Query("category"))
+	args = append(args, limit)
+
+	query := `
+		SELECT
+			v.views,
+			p.id,
+			COALESCE(p.title, '') AS title,
+			COALESCE(p.description, '') AS description,
+			COALESCE(p.price, 0) AS price,
+			COALESCE(p.image, '') AS image,
+			COALESCE(p.category, '') AS category,
+			p.category_id,
+			COALESCE(p.sku, '') AS sku,
+			COALESCE(p.rating, 0) AS rating,
+			COALESCE(p.rating_count, 0) AS rating_count,
+			p.is_active, p.created_at, p.updated_at,
+			p.slug, ` + productPriceColumnsSQL + `,
+			` + availableStockSQL + ` as available_stock,
+			` + stockStatusSQL + ` as stock_status
+		FROM (
+			SELECT product_id, SUM(views) AS views
+			FROM product_view_daily
+			WHERE day > CURRENT_DATE - $1::int
+			GROUP BY product_id
+		) v
+		JOIN products p ON p.id = v.product_id
+		LEFT JOIN inventory i ON p.id = i.product_id
+	` + where + `
+		ORDER BY v.views DESC, p.id
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Trend ürünler alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	items := []models.TrendingProduct{}
+	for rows.Next() {
+		var item models.TrendingProduct
+		var product models.ProductWithStock
+		if err := rows.Scan(
+			&item.Views,
+			&product.ID, &product.Title, &product.Description, &product.Price,
+			&product.Image, &product.Category, &product.CategoryID, &product.SKU, &product.Rating,
+			&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt,
+			&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
+			&product.AvailableStock, &product.StockStatus,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Trend ürünler okunamadı: " + err.Error()})
+			return
+		}
+		item.ProductID = product.ID
+		item.Product = &product
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"days": days, "products": items})
+}
+
+// GetProductViewStats ürünün günlük görüntülenme sayılarını döner; görüntülenmeyen günler 0'dır (?days=30)
+func (h *ProductHandler) GetProductViewStats(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+	days, ok := boundedIntQuery(c, "days", 30, maxViewStatsDays)
+	if !ok {
+		return
+	}
+
+	var exists bool
+	if err := database.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün alınamadı: " + err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT d::date, COALESCE(v.views, 0)
+		FROM generate_series(CURRENT_DATE - ($2::int - 1), CURRENT_DATE, interval '1 day') d
+		LEFT JOIN product_view_daily v ON v.product_id = $1 AND v.day = d::date
+		ORDER BY d
+	`, productID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Görüntüleme istatistikleri alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	stats := []models.ProductViewStat{}
+	total := 0
+	for rows.Next() {
+		var day time.Time
+		var stat models.ProductViewStat
+		if err := rows.Scan(&day, &stat.Views); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Görüntüleme istatistikleri okunamadı: " + err.Error()})
+			return
+		}
+		stat.Day = day.Format("2006-01-02")
+		total += stat.Views
+		stats = append(stats, stat)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product_id": productID, "days": days, "total_views": total, "daily": stats})
+}
@@ -1,45 +1,53 @@
 package handlers
 
 import (
+	"database/sql"
 	"ecommerce-backend/internal/config"
 	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/imaging"
 	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/storage"
+	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
+const avatarBucket = "avatars"
+
 type ProfileHandler struct {
-	cfg *config.Config
+	cfg     *config.Config
+	storage storage.Storage
 }
 
-func NewProfileHandler(cfg *config.Config) *ProfileHandler {
-	return &ProfileHandler{cfg: cfg}
+func NewProfileHandler(cfg *config.Config, store storage.Storage) *ProfileHandler {
+	return &ProfileHandler{cfg: cfg, storage: store}
 }
 
 func (h *ProfileHandler) GetProfile(c *gin.Context) {
 	userID := c.GetString("userID")
 
 	query := `
-		SELECT id, full_name, username, email, is_admin, avatar_url, created_at, updated_at 
+		SELECT id, full_name, username, email, is_admin, avatar_url, avatar_variants, created_at, updated_at 
 		FROM profiles WHERE id = $1
 	`
 
 	var profile models.Profile
+	var avatarVariants []byte
 	err := database.DB.QueryRow(query, userID).Scan(
 		&profile.ID, &profile.FullName, &profile.Username, &profile.Email,
-		&profile.IsAdmin, &profile.AvatarURL, &profile.CreatedAt, &profile.UpdatedAt,
+		&profile.IsAdmin, &profile.AvatarURL, &avatarVariants, &profile.CreatedAt, &profile.UpdatedAt,
 	)
 
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Profil bulunamadı"})
 		return
 	}
+	_ = json.Unmarshal(avatarVariants, &profile.AvatarVariants)
 
 	c.JSON(http.StatusOK, gin.H{"profile": profile})
 }
@@ -60,103 +68,67 @@ func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
 		return
 	}
 
-	// File type kontrolü
-	contentType := header.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "image/") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Sadece resim dosyaları kabul edilir"})
-		return
-	}
-
-	// File extension al
-	ext := filepath.Ext(header.Filename)
-	if ext == "" {
-		ext = ".jpg"
-	}
-
-	// Unique filename oluştur
-	filename := fmt.Sprintf("avatar_%s_%d%s", userID, time.Now().Unix(), ext)
-
-	if h.cfg.SupabaseURL == "" || (h.cfg.SupabaseKey == "" && h.cfg.SupabaseServiceKey == "") {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Supabase yapılandırması eksik"})
-		return
-	}
-
-	// Kullanılacak anahtar (service role varsa onu kullan)
-	authKey := h.cfg.SupabaseServiceKey
-	if authKey == "" {
-		authKey = h.cfg.SupabaseKey
-	}
-
-	// Eski avatar'ı Supabase'den sil (kullanıcı klasörü içindeyse)
-	var oldAvatarURL string
-	database.DB.QueryRow("SELECT avatar_url FROM profiles WHERE id = $1", userID).Scan(&oldAvatarURL)
-	if oldAvatarURL != "" {
-		prefix := "/storage/v1/object/public/avatars/"
-		if idx := strings.Index(oldAvatarURL, prefix); idx != -1 {
-			rel := oldAvatarURL[idx+len(prefix):]
-			if strings.HasPrefix(rel, userID+"/") {
-				deleteURL := strings.TrimRight(h.cfg.SupabaseURL, "/") + "/storage/v1/object/avatars/" + rel
-				req, _ := http.NewRequest(http.MethodDelete, deleteURL, nil)
-				req.Header.Set("Authorization", "Bearer "+authKey)
-				req.Header.Set("apikey", authKey)
-				_, _ = http.DefaultClient.Do(req)
-			}
-		}
-	}
-
-	// avatars/{userID}/{filename} içine yükle
-	objectPath := filepath.ToSlash(filepath.Join(userID, filename))
-	uploadURL := strings.TrimRight(h.cfg.SupabaseURL, "/") + "/storage/v1/object/avatars/" + objectPath + "?upsert=true"
-
-	req, err := http.NewRequest(http.MethodPost, uploadURL, file)
+	// DÜZELTME: Content-Type header'ına güvenilmez; tip içerikten tespit edilir,
+	// EXIF temizlenir, yön düzeltilir ve boyut varyantları üretilir
+	processed, err := imaging.Process(file, imaging.DefaultOptions())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload isteği oluşturulamadı"})
+		respondImageError(c, err)
 		return
 	}
-	req.Header.Set("Authorization", "Bearer "+authKey)
-	req.Header.Set("apikey", authKey)
-	req.Header.Set("Content-Type", contentType)
 
-	resp, err := http.DefaultClient.Do(req)
+	// avatars/{userID}/avatar_{ts}/{varyant} içine yükle
+	prefix := fmt.Sprintf("%s/avatar_%d", userID, time.Now().Unix())
+	uploaded, err := uploadRenditions(c.Request.Context(), h.storage, avatarBucket, prefix, processed)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Supabase'a bağlanılamadı"})
-		return
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Supabase yükleme hatası: %s", strings.TrimSpace(string(body)))})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Avatar yüklenemedi: " + err.Error()})
 		return
 	}
 
-	// Public URL oluştur (bucket public ise)
-	publicURL := strings.TrimRight(h.cfg.SupabaseURL, "/") + "/storage/v1/object/public/avatars/" + objectPath
+	// Eski avatar nesneleri (yeni kayıt başarılı olursa silinir)
+	var oldAvatarURL sql.NullString
+	var oldPaths []string
+	database.DB.QueryRow("SELECT avatar_url, avatar_paths FROM profiles WHERE id = $1", userID).
+		Scan(&oldAvatarURL, pq.Array(&oldPaths))
+
+	variantsJSON, _ := json.Marshal(uploaded.Variants)
 
 	// Database'de avatar URL'ini güncelle
 	updateQuery := `
         UPDATE profiles 
-        SET avatar_url = $1, updated_at = NOW() 
-        WHERE id = $2 
+        SET avatar_url = $1, avatar_variants = $2, avatar_paths = $3, updated_at = NOW() 
+        WHERE id = $4 
         RETURNING avatar_url
     `
 
 	var updatedURL string
-	err = database.DB.QueryRow(updateQuery, publicURL, userID).Scan(&updatedURL)
+	err = database.DB.QueryRow(updateQuery, uploaded.URL, variantsJSON, pq.Array(uploaded.Paths), userID).Scan(&updatedURL)
 	if err != nil {
 		// Geri al
-		deleteURL := strings.TrimRight(h.cfg.SupabaseURL, "/") + "/storage/v1/object/avatars/" + objectPath
-		req2, _ := http.NewRequest(http.MethodDelete, deleteURL, nil)
-		req2.Header.Set("Authorization", "Bearer "+authKey)
-		req2.Header.Set("apikey", authKey)
-		_, _ = http.DefaultClient.Do(req2)
+		deleteObjects(c.Request.Context(), h.storage, avatarBucket, uploaded.Paths)
 
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Avatar URL güncellenemedi"})
 		return
 	}
 
+	// Eski avatar'ı storage'dan sil (kullanıcı klasörü içindeyse)
+	if len(oldPaths) == 0 && oldAvatarURL.String != "" {
+		publicPrefix := h.storage.PublicURL(avatarBucket, "")
+		if strings.HasPrefix(oldAvatarURL.String, publicPrefix) {
+			oldPaths = []string{strings.TrimPrefix(oldAvatarURL.String, publicPrefix)}
+		}
+	}
+	var stale []string
+	for _, p := range oldPaths {
+		if strings.HasPrefix(p, userID+"/") {
+			stale = append(stale, p)
+		}
+	}
+	_ = deleteObjects(c.Request.Context(), h.storage, avatarBucket, stale)
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":    "Avatar başarıyla yüklendi",
-		"avatar_url": updatedURL,
+		"message":         "Avatar başarıyla yüklendi",
+		"avatar_url":      updatedURL,
+		"avatar_variants": uploaded.Variants,
 	})
 }
 func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
@@ -208,3 +180,33 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 		"profile": profile,
 	})
 }
+
+// EKLEME: Terk edilmiş sepet hatırlatmalarından çıkma / tekrar abone olma
+func (h *ProfileHandler) UpdateNotificationPreferences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req models.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updateQuery := `
+		UPDATE profiles 
+		SET cart_reminder_opt_out = $1, updated_at = NOW() 
+		WHERE id = $2 
+		RETURNING cart_reminder_opt_out
+	`
+
+	var optedOut bool
+	err := database.DB.QueryRow(updateQuery, !*req.CartReminders, userID).Scan(&optedOut)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profil bulunamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Bildirim tercihleri güncellendi",
+		"cart_reminders": !optedOut,
+	})
+}
@@ -0,0 +1,111 @@
+// ========================================
+// internal/handlers/purchase_limits.go - MÜŞTERİ / SİPARİŞ BAŞINA SATIN ALMA LİMİTLERİ
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limit türleri
+const (
+	limitMaxPerOrder    = "max_per_order"
+	limitMaxPerCustomer = "max_per_customer"
+)
+
+// purchaseLimitViolation limit aşımının detayları (API'ye aynen döner)
+type purchaseLimitViolation struct {
+	ProductID        int    `json:"product_id"`
+	LimitType        string `json:"limit_type"`
+	Limit            int    `json:"limit"`
+	Requested        int    `json:"requested"`
+	AlreadyPurchased int    `json:"already_purchased"`
+	Remaining        int    `json:"remaining"`
+	WindowDays       *int   `json:"window_days,omitempty"`
+}
+
+// checkPurchaseLimit ürünün sipariş ve müşteri başı limitlerini kontrol eder.
+// quantity, sepette/siparişte olacak toplam miktardır. Limit aşılmıyorsa nil döner.
+// İptal edilmiş siparişler müşteri limitine sayılmaz.
+func checkPurchaseLimit(q queryRower, userID string, productID, quantity int) (*purchaseLimitViolation, error) {
+	var maxPerOrder, maxPerCustomer, windowDays sql.NullInt64
+	err := q.QueryRow(
+		"SELECT max_per_order, max_per_customer, purchase_limit_window_days FROM products WHERE id = $1",
+		productID,
+	).Scan(&maxPerOrder, &maxPerCustomer, &windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxPerOrder.Valid && quantity > int(maxPerOrder.Int64) {
+		return &purchaseLimitViolation{
+			ProductID: productID,
+			LimitType: limitMaxPerOrder,
+			Limit:     int(maxPerOrder.Int64),
+			Requested: quantity,
+			Remaining: int(maxPerOrder.Int64),
+		}, nil
+	}
+
+	if !maxPerCustomer.Valid {
+		return nil, nil
+	}
+
+	var window interface{}
+	if windowDays.Valid {
+		window = windowDays.Int64
+	}
+
+	var purchased int
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(oi.quantity), 0)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.user_id = $1 AND oi.product_id = $2
+		  AND o.status <> 'cancelled'
+		  AND ($3::int IS NULL OR o.created_at > NOW() - make_interval(days => $3::int))
+	`, userID, productID, window).Scan(&purchased)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(maxPerCustomer.Int64)
+	if purchased+quantity <= limit {
+		return nil, nil
+	}
+
+	remaining := limit - purchased
+	if remaining < 0 {
+		remaining = 0
+	}
+	violation := &purchaseLimitViolation{
+		ProductID:        productID,
+		LimitType:        limitMaxPerCustomer,
+		Limit:            limit,
+		Requested:        quantity,
+		AlreadyPurchased: purchased,
+		Remaining:        remaining,
+	}
+	if windowDays.Valid {
+		days := int(windowDays.Int64)
+		violation.WindowDays = &days
+	}
+	return violation, nil
+}
+
+// respondPurchaseLimit "Yetersiz stok" yanıtıyla aynı biçimde limit hatası döner
+func respondPurchaseLimit(c *gin.Context, v *purchaseLimitViolation) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":             "Satın alma limiti aşıldı",
+		"product_id":        v.ProductID,
+		"limit_type":        v.LimitType,
+		"limit":             v.Limit,
+		"requested":         v.Requested,
+		"already_purchased": v.AlreadyPurchased,
+		"remaining":         v.Remaining,
+		"window_days":       v.WindowDays,
+	})
+}
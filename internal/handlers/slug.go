@@ -0,0 +1,45 @@
+// ========================================
+// internal/handlers/slug.go - TÜRKÇE UYUMLU SLUG ÜRETİMİ
+// ========================================
+package handlers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// turkishSlugReplacer Türkçe ve yaygın aksanlı harfleri ASCII karşılıklarına çevirir.
+// strings.ToLower "İ" harfini "i̇" (noktalı) yaptığından dönüşüm küçültmeden önce yapılır.
+var turkishSlugReplacer = strings.NewReplacer(
+	"İ", "i", "I", "i", "ı", "i",
+	"Ç", "c", "ç", "c",
+	"Ğ", "g", "ğ", "g",
+	"Ö", "o", "ö", "o",
+	"Ş", "s", "ş", "s",
+	"Ü", "u", "ü", "u",
+	"Â", "a", "â", "a",
+	"Î", "i", "î", "i",
+	"Û", "u", "û", "u",
+)
+
+// slugify metni küçük harf, rakam ve tirelerden oluşan URL dostu bir slug'a çevirir
+// (ör: "Kadın Giyim & Ayakkabı" -> "kadin-giyim-ayakkabi"). Migration'daki SQL
+// dönüşümüyle aynı sonucu üretir.
+func slugify(s string) string {
+	s = strings.ToLower(turkishSlugReplacer.Replace(strings.TrimSpace(s)))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
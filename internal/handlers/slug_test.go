@@ -0,0 +1,92 @@
+// ========================================
+// internal/handlers/slug_test.go - SLUG ÜRETİMİ TESTLERİ
+// ========================================
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// migrationTranslate 019_product_slugs migration'ındaki TRANSLATE(...) karakter listeleri;
+// test migration dosyasından okur, böylece iki taraftan biri değişirse test kırılır
+func migrationTranslate(t *testing.T) (from, to []rune) {
+	t.Helper()
+	sql, err := os.ReadFile(filepath.Join("..", "database", "migrations", "019_product_slugs.sql"))
+	if err != nil {
+		t.Fatalf("migration okunamadı: %v", err)
+	}
+	m := regexp.MustCompile(`TRANSLATE\(.*?, '([^']+)', '([^']+)'\)`).FindSubmatch(sql)
+	if m == nil {
+		t.Fatal("migration'da TRANSLATE ifadesi bulunamadı")
+	}
+	return []rune(string(m[1])), []rune(string(m[2]))
+}
+
+// migrationSlug migration'daki SQL dönüşümünün Go karşılığı:
+// TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(TRANSLATE(TRIM(s), from, to)), '[^a-z0-9]+', '-', 'g'))
+func migrationSlug(s string, from, to []rune) string {
+	translated := []rune(strings.Trim(s, " "))
+	for i, r := range translated {
+		for j, f := range from {
+			if r == f {
+				translated[i] = to[j]
+				break
+			}
+		}
+	}
+	lowered := strings.ToLower(string(translated))
+	return strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(lowered, "-"), "-")
+}
+
+func TestSlugify(t *testing.T) {
+	from, to := migrationTranslate(t)
+
+	tests := []struct {
+		in, want string
+	}{
+		{"İstanbul Işık", "istanbul-isik"},
+		{"ışık şeker", "isik-seker"},
+		{"ÇĞÖŞÜ çğöşü", "cgosu-cgosu"},
+		{"Kadın Giyim & Ayakkabı", "kadin-giyim-ayakkabi"},
+		{"Kâğıt Havlu ÂÎÛ", "kagit-havlu-aiu"},
+		{"iPhone 15 Pro", "iphone-15-pro"},
+		{"a -- b__c  ..d", "a-b-c-d"},
+		{"  --Merhaba Dünya--  ", "merhaba-dunya"},
+		{"-_-ürün-_-", "urun"},
+		{"Café Crème", "caf-cr-me"},
+		{"!!! ???", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := slugify(tt.in)
+			if got != tt.want {
+				t.Errorf("slugify(%q) = %q, beklenen %q", tt.in, got, tt.want)
+			}
+			if sql := migrationSlug(tt.in, from, to); got != sql {
+				t.Errorf("slugify(%q) = %q, migration dönüşümü %q", tt.in, got, sql)
+			}
+		})
+	}
+}
+
+func TestSlugOrDefault(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Çanta", "canta"},
+		{"!!!", "urun"},
+		{"   ", "urun"},
+		{"---", "urun"},
+	}
+	for _, tt := range tests {
+		if got := slugOrDefault(tt.in); got != tt.want {
+			t.Errorf("slugOrDefault(%q) = %q, beklenen %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+// ========================================
+// internal/handlers/variant.go - ÜRÜN SEÇENEKLERİ VE VARYANTLARI
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// CreateProductOption ürüne seçenek tipi (ör: Beden) ve değerlerini ekler
+func (h *ProductHandler) CreateProductOption(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	var req models.CreateProductOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	var productExists bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", productID).Scan(&productExists)
+	if err != nil || !productExists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	}
+
+	option := models.ProductOption{ProductID: productID, Name: strings.TrimSpace(req.Name), Position: req.Position}
+	err = tx.QueryRow(
+		"INSERT INTO product_options (product_id, name, position) VALUES ($1, $2, $3) RETURNING id",
+		productID, option.Name, option.Position,
+	).Scan(&option.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu isimde bir seçenek zaten var"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Seçenek oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	for i, raw := range req.Values {
+		value := models.ProductOptionValue{OptionID: option.ID, Value: strings.TrimSpace(raw), Position: i}
+		err = tx.QueryRow(
+			"INSERT INTO product_option_values (option_id, value, position) VALUES ($1, $2, $3) RETURNING id",
+			option.ID, value.Value, value.Position,
+		).Scan(&value.ID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Seçenek değerleri tekrar edemez", "value": value.Value})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Seçenek değeri eklenemedi: " + err.Error()})
+			return
+		}
+		option.Values = append(option.Values, value)
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"option": option})
+}
+
+// CreateProductVariant ürünün her seçeneğinden bir değer içeren yeni varyant oluşturur
+func (h *ProductHandler) CreateProductVariant(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	var req models.CreateProductVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	var productExists bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", productID).Scan(&productExists)
+	if err != nil || !productExists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	}
+
+	// Seçenek adı -> değer eşlemesini değer ID'lerine çevir; ürünün tüm seçenekleri verilmeli
+	rows, err := tx.Query(`
+		SELECT o.name, ov.value, ov.id
+		FROM product_options o
+		JOIN product_option_values ov ON ov.option_id = o.id
+		WHERE o.product_id = $1
+	`, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Seçenekler alınamadı: " + err.Error()})
+		return
+	}
+	valueIDs := make(map[string]map[string]int)
+	for rows.Next() {
+		var name, value string
+		var id int
+		if err := rows.Scan(&name, &value, &id); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Seçenekler okunamadı: " + err.Error()})
+			return
+		}
+		if valueIDs[name] == nil {
+			valueIDs[name] = make(map[string]int)
+		}
+		valueIDs[name][value] = id
+	}
+	rows.Close()
+
+	if len(valueIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Önce ürüne seçenek eklenmelidir"})
+		return
+	}
+	if len(req.Options) != len(valueIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Varyant ürünün tüm seçeneklerinden birer değer içermelidir"})
+		return
+	}
+
+	selected := make([]int, 0, len(req.Options))
+	for name, value := range req.Options {
+		id, ok := valueIDs[name][value]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz seçenek değeri", "option": name, "value": value})
+			return
+		}
+		selected = append(selected, id)
+	}
+	sort.Ints(selected)
+
+	// Aynı seçenek kombinasyonuna sahip ikinci bir varyant oluşturulamaz
+	var duplicate bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM product_variants v
+			WHERE v.product_id = $1
+			  AND (SELECT array_agg(option_value_id ORDER BY option_value_id)
+			       FROM product_variant_options WHERE variant_id = v.id) = $2::int[]
+		)
+	`, productID, pq.Array(selected)).Scan(&duplicate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant kontrol edilemedi: " + err.Error()})
+		return
+	}
+	if duplicate {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bu seçenek kombinasyonu için varyant zaten var"})
+		return
+	}
+
+	var variant models.ProductVariant
+	err = tx.QueryRow(`
+		INSERT INTO product_variants (product_id, sku, price, image, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, product_id, sku, price, image, is_active, created_at, updated_at
+	`, productID, req.SKU, req.Price, req.Image, isActive).Scan(
+		&variant.ID, &variant.ProductID, &variant.SKU, &variant.Price, &variant.Image,
+		&variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu SKU zaten kullanılıyor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	for _, valueID := range selected {
+		_, err = tx.Exec(
+			"INSERT INTO product_variant_options (variant_id, option_value_id) VALUES ($1, $2)",
+			variant.ID, valueID,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant seçenekleri eklenemedi: " + err.Error()})
+			return
+		}
+	}
+
+	quantity := 0
+	if req.InitialStock != nil && *req.InitialStock >= 0 {
+		quantity = *req.InitialStock
+	}
+	minLevel := 0
+	if req.MinStockLevel != nil && *req.MinStockLevel >= 0 {
+		minLevel = *req.MinStockLevel
+	}
+	_, err = tx.Exec(`
+		INSERT INTO variant_inventory (variant_id, quantity, reserved_quantity, min_stock_level, updated_at)
+		VALUES ($1, $2, 0, $3, NOW())
+	`, variant.ID, quantity, minLevel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant stoku oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	variant.Options = req.Options
+	variant.AvailableStock = quantity
+	c.JSON(http.StatusCreated, gin.H{"variant": variant})
+}
+
+// UpdateProductVariant varyantın SKU, fiyat, görsel, durum ve stok bilgilerini günceller
+func (h *ProductHandler) UpdateProductVariant(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+	variantID, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz variant ID"})
+		return
+	}
+
+	var req models.UpdateProductVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	var existing models.ProductVariant
+	err = tx.QueryRow(`
+		SELECT id, product_id, sku, price, image, is_active
+		FROM product_variants WHERE id = $1 AND product_id = $2
+		FOR UPDATE
+	`, variantID, productID).Scan(
+		&existing.ID, &existing.ProductID, &existing.SKU, &existing.Price, &existing.Image, &existing.IsActive,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": errVariantNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant alınamadı: " + err.Error()})
+		return
+	}
+
+	// Merge updates
+	if req.SKU != nil {
+		existing.SKU = *req.SKU
+	}
+	if req.ClearPrice {
+		existing.Price = nil
+	} else if req.Price != nil {
+		existing.Price = req.Price
+	}
+	if req.Image != nil {
+		existing.Image = *req.Image
+	}
+	if req.IsActive != nil {
+		existing.IsActive = *req.IsActive
+	}
+
+	var updated models.ProductVariant
+	err = tx.QueryRow(`
+		UPDATE product_variants
+		SET sku = $1, price = $2, image = $3, is_active = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING id, product_id, sku, price, image, is_active, created_at, updated_at
+	`, existing.SKU, existing.Price, existing.Image, existing.IsActive, variantID).Scan(
+		&updated.ID, &updated.ProductID, &updated.SKU, &updated.Price, &updated.Image,
+		&updated.IsActive, &updated.CreatedAt, &updated.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu SKU zaten kullanılıyor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant güncellenemedi: " + err.Error()})
+		return
+	}
+
+	if req.Stock != nil || req.MinStockLevel != nil {
+		_, err = tx.Exec(`
+			INSERT INTO variant_inventory (variant_id, quantity, reserved_quantity, min_stock_level, updated_at)
+			VALUES ($1, COALESCE($2::int, 0), 0, COALESCE($3::int, 0), NOW())
+			ON CONFLICT (variant_id) DO UPDATE SET
+			  quantity = COALESCE($2::int, variant_inventory.quantity),
+			  min_stock_level = COALESCE($3::int, variant_inventory.min_stock_level),
+			  updated_at = NOW()
+		`, variantID, req.Stock, req.MinStockLevel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant stoku güncellenemedi: " + err.Error()})
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variant": updated})
+}
+
+// DeleteProductVariant varyantı pasifleştirir. Geçmiş sipariş ve sepet kayıtları
+// varyanta bağlı olduğu için satır silinmez.
+func (h *ProductHandler) DeleteProductVariant(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+	variantID, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz variant ID"})
+		return
+	}
+
+	result, err := database.DB.Exec(
+		"UPDATE product_variants SET is_active = false, updated_at = NOW() WHERE id = $1 AND product_id = $2",
+		variantID, productID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant pasifleştirilemedi: " + err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": errVariantNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Varyant pasifleştirildi", "variant_id": variantID})
+}
+
+// loadProductOptions ürünün seçeneklerini değerleriyle birlikte sıralı döndürür
+func loadProductOptions(productID int) ([]models.ProductOption, error) {
+	rows, err := database.DB.Query(`
+		SELECT o.id, o.name, o.position, ov.id, ov.value, ov.position
+		FROM product_options o
+		JOIN product_option_values ov ON ov.option_id = o.id
+		WHERE o.product_id = $1
+		ORDER BY o.position, o.id, ov.position, ov.id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var options []models.ProductOption
+	for rows.Next() {
+		var option models.ProductOption
+		var value models.ProductOptionValue
+		if err := rows.Scan(&option.ID, &option.Name, &option.Position, &value.ID, &value.Value, &value.Position); err != nil {
+			return nil, err
+		}
+		value.OptionID = option.ID
+		if n := len(options); n > 0 && options[n-1].ID == option.ID {
+			options[n-1].Values = append(options[n-1].Values, value)
+			continue
+		}
+		option.ProductID = productID
+		option.Values = []models.ProductOptionValue{value}
+		options = append(options, option)
+	}
+	return options, rows.Err()
+}
+
+// loadProductVariants ürünün aktif varyantlarını seçenek değerleri ve stok durumlarıyla döndürür.
+// Stok durumu, diğer kullanıcıların hold'ları düşülmüş mevcut stoktan hesaplanır.
+func loadProductVariants(productID int, basePrice float64, userID string) ([]models.ProductVariant, error) {
+	rows, err := database.DB.Query(`
+		SELECT v.id, v.sku, v.price, v.image, v.is_active, v.created_at, v.updated_at,
+		       st.available as available_stock,
+		       CASE
+		           WHEN i.id IS NULL OR st.available <= 0 THEN 'OUT_OF_STOCK'
+		           WHEN st.available <= i.min_stock_level THEN 'LOW_STOCK'
+		           ELSE 'IN_STOCK'
+		       END as stock_status,
+		       COALESCE(array_agg(o.name ORDER BY o.position, o.id) FILTER (WHERE o.id IS NOT NULL), '{}'),
+		       COALESCE(array_agg(ov.value ORDER BY o.position, o.id) FILTER (WHERE o.id IS NOT NULL), '{}')
+		FROM product_variants v
+		LEFT JOIN variant_inventory i ON i.variant_id = v.id
+		CROSS JOIN LATERAL (
+		    SELECT COALESCE(i.quantity - i.reserved_quantity, 0) - `+heldStockSQL("h.variant_id = v.id", currentUserSQL)+` AS available
+		) st
+		LEFT JOIN product_variant_options vo ON vo.variant_id = v.id
+		LEFT JOIN product_option_values ov ON ov.id = vo.option_value_id
+		LEFT JOIN product_options o ON o.id = ov.option_id
+		WHERE v.product_id = $1 AND v.is_active = true
+		GROUP BY v.id, i.id, st.available
+		ORDER BY v.id
+	`, productID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []models.ProductVariant
+	for rows.Next() {
+		var variant models.ProductVariant
+		var names, values []string
+		err := rows.Scan(
+			&variant.ID, &variant.SKU, &variant.Price, &variant.Image, &variant.IsActive,
+			&variant.CreatedAt, &variant.UpdatedAt, &variant.AvailableStock, &variant.StockStatus,
+			pq.Array(&names), pq.Array(&values),
+		)
+		if err != nil {
+			return nil, err
+		}
+		variant.ProductID = productID
+		variant.EffectivePrice = basePrice
+		if variant.Price != nil {
+			variant.EffectivePrice = *variant.Price
+		}
+		variant.Options = make(map[string]string, len(names))
+		for i, name := range names {
+			if i < len(values) {
+				variant.Options[name] = values[i]
+			}
+		}
+		variants = append(variants, variant)
+	}
+	return variants, rows.Err()
+}
+
+// isUniqueViolation hatanın Postgres unique constraint ihlali olup olmadığını döndürür
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
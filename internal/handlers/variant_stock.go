@@ -0,0 +1,141 @@
+// ========================================
+// internal/handlers/variant_stock.go - VARYANT SEÇİMİ, FİYAT VE STOK YARDIMCILARI
+// ========================================
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errVariantRequired = errors.New("Varyant seçimi gerekli")
+	errVariantNotFound = errors.New("Varyant bulunamadı")
+)
+
+// validateVariant varyantı olan ürünlerde varyant seçimini zorunlu kılar ve seçilen
+// varyantın ürüne ait ve aktif olduğunu doğrular. Varyantsız ürünlerde variantID nil olmalıdır.
+func validateVariant(q queryRower, productID int, variantID *int) error {
+	if variantID == nil {
+		var hasVariants bool
+		err := q.QueryRow(
+			"SELECT EXISTS(SELECT 1 FROM product_variants WHERE product_id = $1 AND is_active = true)",
+			productID,
+		).Scan(&hasVariants)
+		if err != nil {
+			return err
+		}
+		if hasVariants {
+			return errVariantRequired
+		}
+		return nil
+	}
+
+	var exists bool
+	err := q.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM product_variants WHERE id = $1 AND product_id = $2 AND is_active = true)",
+		*variantID, productID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errVariantNotFound
+	}
+	return nil
+}
+
+// unitPriceFor satırın güncel birim fiyatını döndürür (varyant fiyatı varsa o geçerlidir,
+// yoksa ürünün o an geçerli indirimli / normal fiyatı)
+func unitPriceFor(q queryRower, productID int, variantID *int) (float64, error) {
+	var price float64
+	err := q.QueryRow(`
+		SELECT COALESCE(v.price, `+effectivePriceSQL+`)
+		FROM products p
+		LEFT JOIN product_variants v ON v.id = $2::int AND v.product_id = p.id
+		WHERE p.id = $1 AND p.is_active = true AND p.deleted_at IS NULL
+	`, productID, variantID).Scan(&price)
+	return price, err
+}
+
+// lockStock satırın stok kaydını (ürün veya varyant envanteri) transaction sonuna kadar kilitler.
+// Stok kontrolü ile sepet/hold yazımı arasında başka bir işlemin araya girmesini engeller.
+// Envanter kaydı olmayan ürünlerde de işlemlerin sıraya girmesi için önce ürün satırı kilitlenir;
+// FOR NO KEY UPDATE sepet satırlarının foreign key kontrollerini bekletmez.
+func lockStock(tx *sql.Tx, productID int, variantID *int) error {
+	if _, err := tx.Exec("SELECT 1 FROM products WHERE id = $1 FOR NO KEY UPDATE", productID); err != nil {
+		return err
+	}
+	if variantID != nil {
+		_, err := tx.Exec("SELECT 1 FROM variant_inventory WHERE variant_id = $1 FOR UPDATE", *variantID)
+		return err
+	}
+	_, err := tx.Exec("SELECT 1 FROM inventory WHERE product_id = $1 FOR UPDATE", productID)
+	return err
+}
+
+// reserveStock sipariş için stok rezerve eder
+func reserveStock(tx *sql.Tx, productID int, variantID *int, quantity int) error {
+	if variantID != nil {
+		_, err := tx.Exec(`
+			UPDATE variant_inventory 
+			SET reserved_quantity = reserved_quantity + $1, updated_at = NOW() 
+			WHERE variant_id = $2
+		`, quantity, *variantID)
+		return err
+	}
+	_, err := tx.Exec(`
+		UPDATE inventory 
+		SET reserved_quantity = reserved_quantity + $1, updated_at = NOW() 
+		WHERE product_id = $2
+	`, quantity, productID)
+	return err
+}
+
+// variantIDQuery opsiyonel ?variant_id= query parametresini okur
+func variantIDQuery(c *gin.Context) (*int, error) {
+	raw := c.Query("variant_id")
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// sameVariant iki opsiyonel varyant ID'sinin eşit olup olmadığını döndürür
+func sameVariant(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// otherVariantsInCart aynı ürünün sepetteki diğer varyant satırlarının toplam miktarını döndürür.
+// Satın alma limitleri varyant değil ürün bazında uygulanır.
+func otherVariantsInCart(q queryRower, cartID, productID int, variantID *int) (int, error) {
+	var quantity int
+	err := q.QueryRow(`
+		SELECT COALESCE(SUM(quantity), 0) FROM cart_items
+		WHERE cart_id = $1 AND product_id = $2 AND variant_id IS DISTINCT FROM $3::int
+	`, cartID, productID, variantID).Scan(&quantity)
+	return quantity, err
+}
+
+// respondVariantError varyant doğrulama hatalarını HTTP yanıtına çevirir
+func respondVariantError(c *gin.Context, err error) {
+	switch err {
+	case errVariantRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errVariantNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varyant kontrol edilemedi: " + err.Error()})
+	}
+}
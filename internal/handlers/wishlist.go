@@ -0,0 +1,578 @@
+// ========================================
+// internal/handlers/wishlist.go - İSTEK LİSTELERİ VE "SONRA AL"
+// ========================================
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const defaultWishlistName = "Favorilerim"
+
+type WishlistHandler struct {
+	cfg   *config.Config
+	holds cartHolds
+}
+
+func NewWishlistHandler(cfg *config.Config) *WishlistHandler {
+	return &WishlistHandler{
+		cfg:   cfg,
+		holds: newCartHolds(cfg),
+	}
+}
+
+// queryRower hem *sql.DB hem *sql.Tx ile çalışan yardımcılar için
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (h *WishlistHandler) GetWishlists(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	// Varsayılan liste her zaman görünsün
+	if _, err := ensureDefaultWishlist(database.DB, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, name, is_default, is_public, share_token, created_at, updated_at
+		FROM wishlists
+		WHERE user_id = $1
+		ORDER BY is_default DESC, created_at
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listeleri alınamadı: " + err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	wishlists := make([]models.Wishlist, 0)
+	for rows.Next() {
+		var w models.Wishlist
+		if err := rows.Scan(&w.ID, &w.UserID, &w.Name, &w.IsDefault, &w.IsPublic, &w.ShareToken, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			continue
+		}
+		wishlists = append(wishlists, w)
+	}
+
+	if err := loadWishlistItems(wishlists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi ürünleri alınamadı: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"wishlists": wishlists})
+}
+
+func (h *WishlistHandler) CreateWishlist(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req models.CreateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var w models.Wishlist
+	err := database.DB.QueryRow(`
+		INSERT INTO wishlists (user_id, name, is_default, created_at, updated_at)
+		VALUES ($1, $2, false, NOW(), NOW())
+		RETURNING id, user_id, name, is_default, is_public, share_token, created_at, updated_at
+	`, userID, req.Name).Scan(&w.ID, &w.UserID, &w.Name, &w.IsDefault, &w.IsPublic, &w.ShareToken, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi oluşturulamadı: " + err.Error()})
+		return
+	}
+	w.Items = []models.WishlistItem{}
+
+	c.JSON(http.StatusCreated, gin.H{"wishlist": w})
+}
+
+func (h *WishlistHandler) DeleteWishlist(c *gin.Context) {
+	userID := c.GetString("userID")
+	wishlistID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz wishlist ID"})
+		return
+	}
+
+	// Varsayılan liste silinemez
+	result, err := database.DB.Exec(
+		"DELETE FROM wishlists WHERE id = $1 AND user_id = $2 AND is_default = false",
+		wishlistID, userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi silinemedi: " + err.Error()})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "İstek listesi bulunamadı veya varsayılan liste silinemez"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"wishlist_id": wishlistID})
+}
+
+func (h *WishlistHandler) AddWishlistItem(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req models.AddWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlistID, err := resolveWishlistID(database.DB, userID, c.Param("id"))
+	if err != nil {
+		respondWishlistLookupError(c, err)
+		return
+	}
+
+	var productExists bool
+	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_active = true AND deleted_at IS NULL)", req.ProductID).Scan(&productExists)
+	if err != nil || !productExists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO wishlist_items (wishlist_id, product_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (wishlist_id, product_id) DO NOTHING
+	`, wishlistID, req.ProductID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün istek listesine eklenemedi: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wishlist_id": wishlistID,
+		"product_id":  req.ProductID,
+	})
+}
+
+func (h *WishlistHandler) RemoveWishlistItem(c *gin.Context) {
+	userID := c.GetString("userID")
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	wishlistID, err := resolveWishlistID(database.DB, userID, c.Param("id"))
+	if err != nil {
+		respondWishlistLookupError(c, err)
+		return
+	}
+
+	result, err := database.DB.Exec(
+		"DELETE FROM wishlist_items WHERE wishlist_id = $1 AND product_id = $2",
+		wishlistID, productID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün istek listesinden kaldırılamadı: " + err.Error()})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün istek listesinde bulunamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wishlist_id": wishlistID,
+		"product_id":  productID,
+	})
+}
+
+// MoveToCart istek listesindeki ürünü sepete taşır (stok kontrolü ile)
+func (h *WishlistHandler) MoveToCart(c *gin.Context) {
+	userID := c.GetString("userID")
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	req := models.MoveToCartRequest{Quantity: 1}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Quantity == 0 {
+			req.Quantity = 1
+		}
+	}
+
+	// Transaction başlat
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Kilit sırası sepet işlemleriyle aynı: önce sepet satırı
+	cartID, err := findOrCreateCart(tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cart oluşturulamadı: " + err.Error()})
+		return
+	}
+
+	wishlistID, err := resolveWishlistID(tx, userID, c.Param("id"))
+	if err != nil {
+		respondWishlistLookupError(c, err)
+		return
+	}
+
+	result, err := tx.Exec("DELETE FROM wishlist_items WHERE wishlist_id = $1 AND product_id = $2", wishlistID, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün istek listesinden kaldırılamadı: " + err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün istek listesinde bulunamadı"})
+		return
+	}
+
+	// Sepette zaten varsa miktarın üzerine ekle (istek listesi ürün bazında, varyant taşıma anında seçilir)
+	var currentCartQuantity int
+	err = tx.QueryRow(
+		"SELECT quantity FROM cart_items WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::int",
+		cartID, productID, req.VariantID,
+	).Scan(&currentCartQuantity)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet alınamadı: " + err.Error()})
+		return
+	}
+
+	line, err := setCartLine(tx, h.holds, userID, cartID, productID, req.VariantID, currentCartQuantity+req.Quantity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sepet güncellenemedi: " + err.Error()})
+		return
+	}
+
+	switch line.Status {
+	case cartLineNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün bulunamadı"})
+		return
+	case cartLineVariantRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": errVariantRequired.Error()})
+		return
+	case cartLineInsufficientStock:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Yetersiz stok",
+			"available": line.Available,
+			"requested": line.Quantity,
+			"in_cart":   currentCartQuantity,
+			"adding":    req.Quantity,
+		})
+		return
+	case cartLinePurchaseLimit:
+		respondPurchaseLimit(c, line.Limit)
+		return
+	}
+
+	// Transaction commit
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wishlist_id": wishlistID,
+		"cart_item":   line,
+	})
+}
+
+// SaveForLater sepetteki ürünü sepetten çıkarıp istek listesine taşır
+func (h *WishlistHandler) SaveForLater(c *gin.Context) {
+	userID := c.GetString("userID")
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz product ID"})
+		return
+	}
+
+	variantID, err := variantIDQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz variant ID"})
+		return
+	}
+
+	var req models.SaveForLaterRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Transaction başlat
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction başlatılamadı"})
+		return
+	}
+	defer tx.Rollback()
+
+	var cartID int
+	err = tx.QueryRow("SELECT id FROM carts WHERE user_id = $1 FOR UPDATE", userID).Scan(&cartID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sepet bulunamadı"})
+		return
+	}
+
+	result, err := tx.Exec(
+		"DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3::int",
+		cartID, productID, variantID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün sepetten kaldırılamadı: " + err.Error()})
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ürün sepette bulunamadı"})
+		return
+	}
+
+	if err = h.holds.sync(tx, userID, productID, variantID, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Stok tutma kaldırılamadı: " + err.Error()})
+		return
+	}
+
+	wishlistParam := "default"
+	if req.WishlistID != nil {
+		wishlistParam = strconv.Itoa(*req.WishlistID)
+	}
+	wishlistID, err := resolveWishlistID(tx, userID, wishlistParam)
+	if err != nil {
+		respondWishlistLookupError(c, err)
+		return
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO wishlist_items (wishlist_id, product_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (wishlist_id, product_id) DO NOTHING
+	`, wishlistID, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ürün istek listesine eklenemedi: " + err.Error()})
+		return
+	}
+
+	// Transaction commit
+	if err = tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction commit edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wishlist_id": wishlistID,
+		"product_id":  productID,
+	})
+}
+
+// ShareWishlist listeyi herkese açık paylaşım linkiyle açar veya kapatır
+func (h *WishlistHandler) ShareWishlist(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req models.ShareWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlistID, err := resolveWishlistID(database.DB, userID, c.Param("id"))
+	if err != nil {
+		respondWishlistLookupError(c, err)
+		return
+	}
+
+	// Paylaşım kapatılınca token da iptal edilir, eski linkler çalışmaz
+	var token *string
+	if req.IsPublic {
+		newToken, err := generateShareToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Paylaşım linki oluşturulamadı"})
+			return
+		}
+		token = &newToken
+	}
+
+	var w models.Wishlist
+	err = database.DB.QueryRow(`
+		UPDATE wishlists
+		SET is_public = $1,
+		    share_token = CASE WHEN $1 THEN COALESCE(share_token, $2) ELSE NULL END,
+		    updated_at = NOW()
+		WHERE id = $3
+		RETURNING id, user_id, name, is_default, is_public, share_token, created_at, updated_at
+	`, req.IsPublic, token, wishlistID).Scan(&w.ID, &w.UserID, &w.Name, &w.IsDefault, &w.IsPublic, &w.ShareToken, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi güncellenemedi: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"wishlist": w})
+}
+
+// GetSharedWishlist paylaşım token'ı ile herkese açık listeyi döndürür
+func (h *WishlistHandler) GetSharedWishlist(c *gin.Context) {
+	token := c.Param("token")
+
+	var w models.Wishlist
+	err := database.DB.QueryRow(`
+		SELECT id, name, is_default, is_public, created_at, updated_at
+		FROM wishlists
+		WHERE share_token = $1 AND is_public = true
+	`, token).Scan(&w.ID, &w.Name, &w.IsDefault, &w.IsPublic, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "İstek listesi bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi alınamadı: " + err.Error()})
+		return
+	}
+
+	wishlists := []models.Wishlist{w}
+	if err := loadWishlistItems(wishlists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi ürünleri alınamadı: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"wishlist": wishlists[0]})
+}
+
+// ensureDefaultWishlist kullanıcının varsayılan listesini döndürür, yoksa oluşturur
+func ensureDefaultWishlist(q queryRower, userID string) (int, error) {
+	var wishlistID int
+	err := q.QueryRow(`
+		INSERT INTO wishlists (user_id, name, is_default, created_at, updated_at)
+		VALUES ($1, $2, true, NOW(), NOW())
+		ON CONFLICT (user_id) WHERE is_default DO UPDATE SET updated_at = wishlists.updated_at
+		RETURNING id
+	`, userID, defaultWishlistName).Scan(&wishlistID)
+	return wishlistID, err
+}
+
+// resolveWishlistID route parametresini ("default" veya sayısal ID) kullanıcıya
+// ait liste ID'sine çevirir. Liste kullanıcıya ait değilse sql.ErrNoRows döner.
+func resolveWishlistID(q queryRower, userID, param string) (int, error) {
+	if param == "" || param == "default" {
+		return ensureDefaultWishlist(q, userID)
+	}
+
+	wishlistID, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, err
+	}
+
+	var ownedID int
+	err = q.QueryRow("SELECT id FROM wishlists WHERE id = $1 AND user_id = $2", wishlistID, userID).Scan(&ownedID)
+	return ownedID, err
+}
+
+func respondWishlistLookupError(c *gin.Context, err error) {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz wishlist ID"})
+		return
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "İstek listesi bulunamadı"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "İstek listesi alınamadı: " + err.Error()})
+}
+
+// loadWishlistItems listelerin ürünlerini güncel fiyat ve stok durumuyla doldurur
+func loadWishlistItems(wishlists []models.Wishlist) error {
+	if len(wishlists) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(wishlists))
+	index := make(map[int]int, len(wishlists))
+	for i := range wishlists {
+		ids[i] = int64(wishlists[i].ID)
+		index[wishlists[i].ID] = i
+		wishlists[i].Items = []models.WishlistItem{}
+	}
+
+	query := `
+		SELECT
+			wi.id, wi.wishlist_id, wi.product_id, wi.created_at,
+			p.id,
+			COALESCE(p.title, '') AS title,
+			COALESCE(p.description, '') AS description,
+			COALESCE(p.price, 0) AS price,
+			COALESCE(p.image, '') AS image,
+			COALESCE(p.category, '') AS category,
+			COALESCE(p.sku, '') AS sku,
+			COALESCE(p.rating, 0) AS rating,
+			COALESCE(p.rating_count, 0) AS rating_count,
+			p.is_active, p.created_at, p.updated_at, p.deleted_at,
+			p.slug, ` + productPriceColumnsSQL + `,
+			` + availableStockSQL + ` as available_stock,
+			` + stockStatusSQL + ` as stock_status
+		FROM wishlist_items wi
+		JOIN products p ON p.id = wi.product_id
+		LEFT JOIN inventory i ON p.id = i.product_id
+		WHERE wi.wishlist_id = ANY($1)
+		ORDER BY wi.created_at DESC
+	`
+
+	rows, err := database.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.WishlistItem
+		var product models.ProductWithStock
+		err := rows.Scan(
+			&item.ID, &item.WishlistID, &item.ProductID, &item.CreatedAt,
+			&product.ID, &product.Title, &product.Description, &product.Price,
+			&product.Image, &product.Category, &product.SKU, &product.Rating,
+			&product.RatingCount, &product.IsActive, &product.CreatedAt, &product.UpdatedAt, &product.DeletedAt,
+			&product.Slug, &product.EffectivePrice, &product.OriginalPrice,
+			&product.AvailableStock, &product.StockStatus,
+		)
+		if err != nil {
+			continue
+		}
+
+		item.Product = &product
+		if i, ok := index[item.WishlistID]; ok {
+			wishlists[i].Items = append(wishlists[i].Items, item)
+		}
+	}
+
+	return rows.Err()
+}
+
+func generateShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,200 @@
+// ========================================
+// internal/imaging/imaging.go - YÜKLENEN GÖRSELLER İÇİN İŞLEME HATTI
+// ========================================
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif" // image.Decode için gif desteği
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // image.Decode için webp desteği
+)
+
+var (
+	ErrNotImage          = errors.New("Sadece resim dosyaları kabul edilir")
+	ErrFileTooLarge      = errors.New("Dosya boyutu çok büyük")
+	ErrDimensionsTooBig  = errors.New("Görsel çözünürlüğü çok büyük")
+	ErrUnsupportedFormat = errors.New("Desteklenmeyen görsel formatı")
+)
+
+// Size üretilecek yeniden boyutlandırılmış bir varyantı tanımlar; görsel en uzun
+// kenarı MaxDim olacak şekilde küçültülür (büyütülmez)
+type Size struct {
+	Name   string
+	MaxDim int
+}
+
+// Options işleme limitleri ve üretilecek boyutlar
+type Options struct {
+	MaxBytes     int64
+	MaxPixels    int
+	MaxDimension int
+	Sizes        []Size
+	// WebPFrom WebP varyantının hangi boyuttan üretileceği; boşsa WebP üretilmez
+	WebPFrom    string
+	JPEGQuality int
+}
+
+// DefaultOptions avatar ve ürün görselleri için ortak varsayılanlar
+func DefaultOptions() Options {
+	return Options{
+		MaxBytes:     5 * 1024 * 1024,
+		MaxPixels:    40_000_000,
+		MaxDimension: 10_000,
+		Sizes: []Size{
+			{Name: "thumbnail", MaxDim: 150},
+			{Name: "medium", MaxDim: 600},
+			{Name: "large", MaxDim: 1200},
+		},
+		WebPFrom:    "large",
+		JPEGQuality: 85,
+	}
+}
+
+// Rendition işlenmiş, yüklenmeye hazır tek bir görsel çıktısı
+type Rendition struct {
+	Name        string
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Result işleme sonucu; Renditions Options.Sizes sırasını izler, varsa WebP en sondadır
+type Result struct {
+	Format     string
+	Width      int
+	Height     int
+	Renditions []Rendition
+}
+
+// allowedTypes içerikten tespit edilen (istemcinin gönderdiği değil) MIME tipleri
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Process görseli okur, gerçek tipini içerikten tespit eder, boyut/çözünürlük
+// limitlerini uygular, EXIF yönüne göre döndürür ve varyantları yeniden kodlar.
+// Yeniden kodlama EXIF dahil tüm metadata'yı atar.
+func Process(r io.Reader, opts Options) (*Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, opts.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > opts.MaxBytes {
+		return nil, ErrFileTooLarge
+	}
+
+	if !allowedTypes[http.DetectContentType(data)] {
+		return nil, ErrNotImage
+	}
+
+	// Decompression bomb koruması: piksel verisini açmadan önce başlıktaki boyutlara bak
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 ||
+		cfg.Width > opts.MaxDimension || cfg.Height > opts.MaxDimension ||
+		cfg.Width*cfg.Height > opts.MaxPixels {
+		return nil, ErrDimensionsTooBig
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	img := toNRGBA(src)
+	if format == "jpeg" {
+		img = applyOrientation(img, exifOrientation(data))
+	}
+
+	bounds := img.Bounds()
+	result := &Result{Format: format, Width: bounds.Dx(), Height: bounds.Dy()}
+	opaque := img.Opaque()
+
+	var webpSource image.Image
+	for _, size := range opts.Sizes {
+		resized := fit(img, size.MaxDim)
+
+		rendition := Rendition{Name: size.Name, Width: resized.Bounds().Dx(), Height: resized.Bounds().Dy()}
+		var buf bytes.Buffer
+		if opaque {
+			rendition.ContentType, rendition.Ext = "image/jpeg", ".jpg"
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: opts.JPEGQuality})
+		} else {
+			// Şeffaflığı korumak için PNG
+			rendition.ContentType, rendition.Ext = "image/png", ".png"
+			err = png.Encode(&buf, resized)
+		}
+		if err != nil {
+			return nil, err
+		}
+		rendition.Data = buf.Bytes()
+		result.Renditions = append(result.Renditions, rendition)
+
+		if size.Name == opts.WebPFrom {
+			webpSource = resized
+		}
+	}
+
+	if webpSource != nil {
+		var buf bytes.Buffer
+		if err := nativewebp.Encode(&buf, webpSource, nil); err != nil {
+			return nil, err
+		}
+		result.Renditions = append(result.Renditions, Rendition{
+			Name:        "webp",
+			ContentType: "image/webp",
+			Ext:         ".webp",
+			Width:       webpSource.Bounds().Dx(),
+			Height:      webpSource.Bounds().Dy(),
+			Data:        buf.Bytes(),
+		})
+	}
+
+	return result, nil
+}
+
+// fit görseli en uzun kenarı maxDim olacak şekilde orantılı küçültür
+func fit(src *image.NRGBA, maxDim int) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if maxDim <= 0 || (w <= maxDim && h <= maxDim) {
+		return src
+	}
+
+	nw, nh := maxDim, maxDim
+	if w >= h {
+		nh = max(1, h*maxDim/w)
+	} else {
+		nw = max(1, w*maxDim/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, nw, nh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// toNRGBA görseli (0,0) başlangıçlı NRGBA'ya çevirir; GIF paletleri vb. burada açılır
+func toNRGBA(src image.Image) *image.NRGBA {
+	if img, ok := src.(*image.NRGBA); ok && img.Bounds().Min == (image.Point{}) {
+		return img
+	}
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
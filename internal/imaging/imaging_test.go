@@ -0,0 +1,259 @@
+// ========================================
+// internal/imaging/imaging_test.go - GÖRSEL İŞLEME HATTI TESTLERİ
+// ========================================
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage w x h boyutunda, her pikselin R kanalı x + y*w olan opak bir görsel üretir
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x + y*w), A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png kodlanamadı: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// encodeJPEG görseli JPEG olarak kodlar; segment verilmişse SOI'den hemen sonra eklenir
+func encodeJPEG(t *testing.T, img image.Image, segment []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatalf("jpeg kodlanamadı: %v", err)
+	}
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// exifTIFF tek girdili (orientation) IFD0 içeren TIFF bloğu
+func exifTIFF(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+	return tiff
+}
+
+// app1Segment payload'ı "Exif\0\0" önekiyle bir APP1 segmentine sarar
+func app1Segment(tiff []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	img := testImage(4, 2)
+	valid := app1Segment(exifTIFF(binary.LittleEndian, 6))
+
+	truncatedIFD := exifTIFF(binary.BigEndian, 6)
+	binary.BigEndian.PutUint16(truncatedIFD[8:], 5)       // 5 girdi var denir, yalnızca 1 tane bulunur
+	binary.BigEndian.PutUint16(truncatedIFD[10:], 0x0100) // ve o da orientation değil
+
+	badOffset := exifTIFF(binary.LittleEndian, 6)
+	binary.LittleEndian.PutUint32(badOffset[4:], 1<<30)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"boş veri", nil, 1},
+		{"JPEG değil", encodePNG(t, img), 1},
+		{"yalnızca SOI", []byte{0xFF, 0xD8}, 1},
+		{"EXIF yok", encodeJPEG(t, img, nil), 1},
+		{"little endian", encodeJPEG(t, img, valid), 6},
+		{"big endian", encodeJPEG(t, img, app1Segment(exifTIFF(binary.BigEndian, 8))), 8},
+		{"aralık dışı değer", encodeJPEG(t, img, app1Segment(exifTIFF(binary.LittleEndian, 9))), 1},
+		{"sıfır değer", encodeJPEG(t, img, app1Segment(exifTIFF(binary.LittleEndian, 0))), 1},
+		{"geçersiz bayt sırası", encodeJPEG(t, img, app1Segment(append([]byte("XX"), exifTIFF(binary.LittleEndian, 6)[2:]...))), 1},
+		{"IFD girdileri kesik", encodeJPEG(t, img, app1Segment(truncatedIFD)), 1},
+		{"IFD offset'i dosya dışında", encodeJPEG(t, img, app1Segment(badOffset)), 1},
+		{"kısa TIFF başlığı", encodeJPEG(t, img, app1Segment([]byte("II*"))), 1},
+		{"segment uzunluğu dosyadan büyük", append([]byte{0xFF, 0xD8}, valid[:10]...), 1},
+		{"segment uzunluğu 2'den küçük", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0x00, 0x00}, 1},
+		{"marker beklenirken veri", []byte{0xFF, 0xD8, 0x00, 0x00, 0x00, 0x00}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exifOrientation(tt.data); got != tt.want {
+				t.Errorf("exifOrientation = %d, beklenen %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExifOrientationTruncated geçerli bir EXIF'li JPEG'in her önekinde ayrıştırıcının
+// panic olmadan 1 veya okunan değeri döndüğünü doğrular
+func TestExifOrientationTruncated(t *testing.T) {
+	data := encodeJPEG(t, testImage(4, 2), app1Segment(exifTIFF(binary.BigEndian, 3)))
+	for n := 0; n <= len(data); n++ {
+		if got := exifOrientation(data[:n]); got != 1 && got != 3 {
+			t.Fatalf("önek %d: exifOrientation = %d", n, got)
+		}
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// Kaynak (3x2):
+	//   0 1 2
+	//   3 4 5
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{2, [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{3, [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{4, [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{5, [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{6, [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{7, [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{8, [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+		{9, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+	}
+
+	for _, tt := range tests {
+		got := applyOrientation(testImage(3, 2), tt.orientation)
+		h, w := len(tt.want), len(tt.want[0])
+		if got.Bounds().Dx() != w || got.Bounds().Dy() != h {
+			t.Errorf("orientation %d: boyut %dx%d, beklenen %dx%d", tt.orientation, got.Bounds().Dx(), got.Bounds().Dy(), w, h)
+			continue
+		}
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if r := got.NRGBAAt(x, y).R; r != tt.want[y][x] {
+					t.Errorf("orientation %d: (%d,%d) = %d, beklenen %d", tt.orientation, x, y, r, tt.want[y][x])
+				}
+			}
+		}
+	}
+}
+
+// pngWithHeaderSize 1x1 bir PNG'nin IHDR boyutlarını değiştirir; piksel verisi açılmadan
+// yalnızca başlığı okuyan DecodeConfig büyük boyutları görür (decompression bomb)
+func pngWithHeaderSize(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	data := encodePNG(t, testImage(1, 1))
+	// 8 bayt imza + 4 uzunluk + "IHDR"
+	ihdr := data[16 : 16+13]
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	binary.BigEndian.PutUint32(data[16+13:], crc32.ChecksumIEEE(data[12:16+13]))
+	return data
+}
+
+func TestProcessRejects(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxDimension = 100
+	opts.MaxPixels = 5000
+
+	tests := []struct {
+		name string
+		data []byte
+		opts Options
+		want error
+	}{
+		{"metin dosyası", []byte("merhaba dünya"), opts, ErrNotImage},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), opts, ErrNotImage},
+		{"bozuk PNG gövdesi", append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 32)...), opts, ErrUnsupportedFormat},
+		{"bayt sınırı", encodePNG(t, testImage(10, 10)), Options{MaxBytes: 16, MaxPixels: 5000, MaxDimension: 100}, ErrFileTooLarge},
+		{"kenar sınırı", encodePNG(t, testImage(101, 1)), opts, ErrDimensionsTooBig},
+		{"piksel sınırı", encodePNG(t, testImage(80, 80)), opts, ErrDimensionsTooBig},
+		{"başlıkta dev boyut", pngWithHeaderSize(t, 100000, 100000), opts, ErrDimensionsTooBig},
+		{"başlıkta sıfır boyut", pngWithHeaderSize(t, 0, 10), opts, ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(bytes.NewReader(tt.data), tt.opts); err != tt.want {
+				t.Errorf("Process hata = %v, beklenen %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessAppliesOrientation(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Sizes = []Size{{Name: "thumbnail", MaxDim: 150}}
+	opts.WebPFrom = ""
+
+	tests := []struct {
+		name          string
+		segment       []byte
+		width, height int
+	}{
+		{"EXIF yok", nil, 40, 20},
+		{"90 derece", app1Segment(exifTIFF(binary.LittleEndian, 6)), 20, 40},
+		{"kesik EXIF yok sayılır", app1Segment(exifTIFF(binary.LittleEndian, 6)[:12]), 40, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(bytes.NewReader(encodeJPEG(t, testImage(40, 20), tt.segment)), opts)
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if result.Format != "jpeg" || result.Width != tt.width || result.Height != tt.height {
+				t.Errorf("sonuç %s %dx%d, beklenen jpeg %dx%d", result.Format, result.Width, result.Height, tt.width, tt.height)
+			}
+			if len(result.Renditions) != 1 || result.Renditions[0].ContentType != "image/jpeg" {
+				t.Errorf("beklenmeyen varyantlar: %+v", result.Renditions)
+			}
+		})
+	}
+}
+
+func TestProcessKeepsTransparency(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 100))
+	opts := DefaultOptions()
+	opts.Sizes = []Size{{Name: "thumbnail", MaxDim: 150}}
+	opts.WebPFrom = "thumbnail"
+
+	result, err := Process(bytes.NewReader(encodePNG(t, img)), opts)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if len(result.Renditions) != 2 {
+		t.Fatalf("%d varyant, beklenen 2", len(result.Renditions))
+	}
+	thumb, webp := result.Renditions[0], result.Renditions[1]
+	if thumb.ContentType != "image/png" || thumb.Width != 150 || thumb.Height != 50 {
+		t.Errorf("thumbnail %s %dx%d, beklenen image/png 150x50", thumb.ContentType, thumb.Width, thumb.Height)
+	}
+	if webp.ContentType != "image/webp" || webp.Width != 150 {
+		t.Errorf("webp %s %dx%d", webp.ContentType, webp.Width, webp.Height)
+	}
+}
@@ -0,0 +1,203 @@
+// ========================================
+// internal/jobs/abandoned_cart.go - TERK EDİLMİŞ SEPET HATIRLATMALARI
+// ========================================
+package jobs
+
+import (
+	"context"
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/notify"
+	"log"
+	"time"
+)
+
+// Hatırlatma olay durumları
+const (
+	AbandonmentStatusSent     = "sent"
+	AbandonmentStatusFailed   = "failed"
+	AbandonmentStatusOptedOut = "opted_out"
+)
+
+// Tek çalıştırmada işlenecek maksimum sepet sayısı
+const abandonedCartBatchSize = 100
+
+// AbandonedCartJob belirli süre dokunulmamış ve içinde stokta ürün bulunan
+// sepetleri bulur, olay kaydı oluşturur ve hatırlatma gönderir.
+// Kullanıcı sepete tekrar dokunduğunda (carts.updated_at) sayaç sıfırlanır.
+type AbandonedCartJob struct {
+	IdleAfter    time.Duration
+	Interval     time.Duration
+	MaxReminders int
+	Notifier     notify.Notifier
+}
+
+// Start job'ı arka planda ctx iptal edilene kadar periyodik olarak çalıştırır
+func (j *AbandonedCartJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.RunOnce(ctx); err != nil {
+				log.Printf("Abandoned cart job failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+type abandonedCart struct {
+	cartID    int
+	userID    string
+	email     string
+	optedOut  bool
+	idleSince time.Time
+	sentCount int
+}
+
+// RunOnce aday sepetleri bir kez tarar ve hatırlatmaları gönderir
+func (j *AbandonedCartJob) RunOnce(ctx context.Context) error {
+	idleSeconds := j.IdleAfter.Seconds()
+
+	// Aynı "terk" dönemi içindeki olaylar: sepetin son aktivitesinden sonra oluşanlar
+	query := `
+		WITH candidates AS (
+			SELECT
+				ca.id AS cart_id,
+				ca.user_id,
+				COALESCE(pr.email, '') AS email,
+				COALESCE(pr.cart_reminder_opt_out, false) AS opted_out,
+				COALESCE(ca.updated_at, ca.created_at) AS idle_since,
+				(
+					SELECT COUNT(*) FROM cart_abandonment_events e
+					WHERE e.cart_id = ca.id AND e.created_at > COALESCE(ca.updated_at, ca.created_at)
+				) AS sent_count,
+				(
+					SELECT MAX(e.created_at) FROM cart_abandonment_events e
+					WHERE e.cart_id = ca.id AND e.created_at > COALESCE(ca.updated_at, ca.created_at)
+				) AS last_event_at
+			FROM carts ca
+			LEFT JOIN profiles pr ON pr.id = ca.user_id
+			WHERE COALESCE(ca.updated_at, ca.created_at) < NOW() - make_interval(secs => $1)
+			  AND EXISTS (
+				SELECT 1
+				FROM cart_items ci
+				JOIN products p ON p.id = ci.product_id
+				LEFT JOIN inventory i ON i.product_id = p.id AND ci.variant_id IS NULL
+				LEFT JOIN product_variants v ON v.id = ci.variant_id
+				LEFT JOIN variant_inventory vi ON vi.variant_id = ci.variant_id
+				WHERE ci.cart_id = ca.id
+				  AND p.is_active = true AND p.deleted_at IS NULL
+				  -- Varyantlı satırlarda stok varyantın kendi envanterinden gelir
+				  AND CASE WHEN ci.variant_id IS NULL
+				           THEN COALESCE(i.quantity - i.reserved_quantity, 0) > 0
+				           ELSE COALESCE(v.is_active, false) AND COALESCE(vi.quantity - vi.reserved_quantity, 0) > 0
+				      END
+			  )
+		)
+		SELECT cart_id, user_id, email, opted_out, idle_since, sent_count
+		FROM candidates
+		WHERE sent_count < $2
+		  AND (last_event_at IS NULL OR last_event_at < NOW() - make_interval(secs => $1))
+		  AND NOT (opted_out AND sent_count > 0)
+		ORDER BY idle_since
+		LIMIT $3
+	`
+
+	rows, err := database.DB.QueryContext(ctx, query, idleSeconds, j.MaxReminders, abandonedCartBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var carts []abandonedCart
+	for rows.Next() {
+		var ac abandonedCart
+		if err := rows.Scan(&ac.cartID, &ac.userID, &ac.email, &ac.optedOut, &ac.idleSince, &ac.sentCount); err != nil {
+			continue
+		}
+		carts = append(carts, ac)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, ac := range carts {
+		if err := j.handleCart(ctx, ac); err != nil {
+			log.Printf("Abandoned cart %d could not be processed: %v", ac.cartID, err)
+		}
+	}
+
+	return nil
+}
+
+func (j *AbandonedCartJob) handleCart(ctx context.Context, ac abandonedCart) error {
+	reminderNumber := ac.sentCount + 1
+
+	// Opt-out kullanıcılar için sadece olay kaydı tutulur, bildirim gönderilmez
+	if ac.optedOut {
+		return recordAbandonmentEvent(ctx, ac, reminderNumber, AbandonmentStatusOptedOut, nil)
+	}
+
+	items, err := loadReminderItems(ctx, ac.cartID)
+	if err != nil {
+		return err
+	}
+
+	reminder := notify.CartReminder{
+		CartID:         ac.cartID,
+		UserID:         ac.userID,
+		Email:          ac.email,
+		ReminderNumber: reminderNumber,
+		IdleSince:      ac.idleSince,
+		Items:          items,
+	}
+
+	if sendErr := j.Notifier.SendCartReminder(ctx, reminder); sendErr != nil {
+		return recordAbandonmentEvent(ctx, ac, reminderNumber, AbandonmentStatusFailed, sendErr)
+	}
+	return recordAbandonmentEvent(ctx, ac, reminderNumber, AbandonmentStatusSent, nil)
+}
+
+func loadReminderItems(ctx context.Context, cartID int) ([]notify.CartReminderItem, error) {
+	rows, err := database.DB.QueryContext(ctx, `
+		SELECT ci.product_id, COALESCE(p.title, ''), ci.quantity, COALESCE(p.price, 0)
+		FROM cart_items ci
+		JOIN products p ON p.id = ci.product_id
+		WHERE ci.cart_id = $1 AND p.is_active = true AND p.deleted_at IS NULL
+		ORDER BY ci.created_at DESC
+	`, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []notify.CartReminderItem
+	for rows.Next() {
+		var item notify.CartReminderItem
+		if err := rows.Scan(&item.ProductID, &item.Title, &item.Quantity, &item.Price); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func recordAbandonmentEvent(ctx context.Context, ac abandonedCart, reminderNumber int, status string, sendErr error) error {
+	var errText *string
+	if sendErr != nil {
+		msg := sendErr.Error()
+		errText = &msg
+	}
+
+	_, err := database.DB.ExecContext(ctx, `
+		INSERT INTO cart_abandonment_events (cart_id, user_id, reminder_number, status, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, ac.cartID, ac.userID, reminderNumber, status, errText)
+	return err
+}
@@ -0,0 +1,40 @@
+// ========================================
+// internal/jobs/cart_holds.go - SÜRESİ DOLAN SEPET HOLD'LARINI TEMİZLE
+// ========================================
+package jobs
+
+import (
+	"context"
+	"ecommerce-backend/internal/database"
+	"log"
+	"time"
+)
+
+// CartHoldCleanupJob süresi dolmuş cart_holds kayıtlarını periyodik olarak siler.
+// Stok hesaplamaları zaten expires_at'e baktığı için bu sadece tabloyu küçük tutar.
+type CartHoldCleanupJob struct {
+	Interval time.Duration
+}
+
+func (j *CartHoldCleanupJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := j.RunOnce(ctx); err != nil {
+					log.Printf("Cart hold cleanup failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (j *CartHoldCleanupJob) RunOnce(ctx context.Context) error {
+	_, err := database.DB.ExecContext(ctx, "DELETE FROM cart_holds WHERE expires_at <= NOW()")
+	return err
+}
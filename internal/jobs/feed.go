@@ -0,0 +1,37 @@
+// ========================================
+// internal/jobs/feed.go - ÜRÜN FEED'LERİNİ PERİYODİK YENİDEN ÜRET
+// ========================================
+package jobs
+
+import (
+	"context"
+	"ecommerce-backend/internal/feed"
+	"log"
+	"time"
+)
+
+// FeedRefreshJob Google Merchant ve JSON katalog feed'lerini belirli aralıklarla yeniden üretir.
+// İlk üretim hemen yapılır; böylece ilk feed isteği veritabanını beklemez.
+type FeedRefreshJob struct {
+	Interval time.Duration
+	Cache    *feed.Cache
+}
+
+func (j *FeedRefreshJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.Cache.Refresh(ctx); err != nil {
+				log.Printf("Product feed refresh failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
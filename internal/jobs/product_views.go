@@ -0,0 +1,44 @@
+// ========================================
+// internal/jobs/product_views.go - ESKİ GÖRÜNTÜLEME KAYITLARINI TEMİZLE
+// ========================================
+package jobs
+
+import (
+	"context"
+	"ecommerce-backend/internal/database"
+	"log"
+	"time"
+)
+
+// ProductViewCleanupJob Retention süresinden eski görüntüleyen kayıtlarını siler.
+// Anonim oturum ve IP kayıtları birikmesin diye çalışır; günlük sayaçlar (product_view_daily) korunur.
+type ProductViewCleanupJob struct {
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+func (j *ProductViewCleanupJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := j.RunOnce(ctx); err != nil {
+					log.Printf("Product view cleanup failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (j *ProductViewCleanupJob) RunOnce(ctx context.Context) error {
+	_, err := database.DB.ExecContext(ctx,
+		"DELETE FROM product_viewers WHERE viewed_at < NOW() - make_interval(secs => $1::float8)",
+		j.Retention.Seconds(),
+	)
+	return err
+}
@@ -0,0 +1,165 @@
+// ========================================
+// internal/jobs/recommendations.go - İLGİLİ ÜRÜN ÖNERİLERİNİ PERİYODİK HESAPLA
+// ========================================
+package jobs
+
+import (
+	"context"
+	"ecommerce-backend/internal/database"
+	"log"
+	"time"
+)
+
+// recommendationLockKey birden çok instance aynı anda hesaplamasın diye kullanılan advisory lock anahtarı
+const recommendationLockKey = "product_recommendations"
+
+// Skor ağırlıkları: birlikte satın alma en güçlü sinyaldir; kategori ve fiyat yakınlığı
+// sipariş geçmişi olmayan (yeni) ürünlerin de öneri almasını sağlar
+const (
+	recommendationCoPurchaseWeight = 3.0
+	recommendationCategoryWeight   = 1.0
+	recommendationPriceWeight      = 0.5
+)
+
+// RecommendationJob her ürün için ilgili ürünleri skorlayıp product_recommendations
+// tablosuna yazar; GET /products/:id/related yalnızca bu tablodan okur.
+//
+//   - Birlikte satın alma: son Lookback içindeki iptal edilmemiş siparişlerde iki ürünün
+//     birlikte geçme sayısı, ürünlerin sipariş sayılarına göre normalize edilir (kosinüs)
+//   - Kategori: aynı kategori 1, aynı üst kategori altındaki kardeş kategori 0.5
+//   - Fiyat yakınlığı: 1 - |fiyat farkı| / büyük fiyat
+type RecommendationJob struct {
+	Interval   time.Duration
+	Lookback   time.Duration // <= 0 ise tüm sipariş geçmişi
+	PerProduct int
+}
+
+func (j *RecommendationJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.RunOnce(ctx); err != nil {
+				log.Printf("Product recommendation job failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce önerileri yeniden hesaplar; tablo tek transaction'da değiştirildiği için
+// okuyucular hesaplama sırasında da eski önerileri görür
+func (j *RecommendationJob) RunOnce(ctx context.Context) error {
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err = tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock(hashtext($1))", recommendationLockKey).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		// Başka bir instance şu an hesaplıyor
+		return nil
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM product_recommendations"); err != nil {
+		return err
+	}
+
+	query := `
+		WITH active AS (
+			SELECT p.id, COALESCE(p.price, 0)::float8 AS price, p.category_id, c.parent_id
+			FROM products p
+			LEFT JOIN categories c ON c.id = p.category_id
+			WHERE p.is_active = true AND p.deleted_at IS NULL
+		),
+		order_products AS (
+			SELECT DISTINCT oi.order_id, oi.product_id
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.status <> 'cancelled'
+			  AND ($1::float8 <= 0 OR o.created_at >= NOW() - make_interval(secs => $1::float8))
+		),
+		product_orders AS (
+			SELECT product_id, COUNT(*) AS orders FROM order_products GROUP BY product_id
+		),
+		co_purchases AS (
+			SELECT a.product_id, b.product_id AS related_id, COUNT(*) AS together
+			FROM order_products a
+			JOIN order_products b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+			GROUP BY a.product_id, b.product_id
+		),
+		-- Kategori adayları ürün başına sınırlanır; büyük kategorilerde tüm çiftler skorlanmaz
+		category_neighbours AS (
+			SELECT product_id, related_id FROM (
+				SELECT a.id AS product_id, b.id AS related_id,
+				       ROW_NUMBER() OVER (
+				           PARTITION BY a.id
+				           ORDER BY (b.category_id = a.category_id) DESC, ABS(b.price - a.price), b.id
+				       ) AS rn
+				FROM active a
+				JOIN active b ON b.id <> a.id
+				 AND (b.category_id = a.category_id OR b.parent_id = a.parent_id)
+			) n
+			WHERE rn <= $2
+		),
+		candidates AS (
+			SELECT product_id, related_id FROM co_purchases
+			UNION
+			SELECT product_id, related_id FROM category_neighbours
+		),
+		scored AS (
+			SELECT c.product_id, c.related_id,
+			       COALESCE(cp.together, 0) AS together,
+			       $3 * COALESCE(cp.together / SQRT(pa.orders * pb.orders), 0)
+			       + $4 * CASE
+			                  WHEN b.category_id = a.category_id THEN 1
+			                  WHEN b.parent_id = a.parent_id THEN 0.5
+			                  ELSE 0
+			              END
+			       + $5 * CASE
+			                  WHEN GREATEST(a.price, b.price) > 0 THEN 1 - ABS(a.price - b.price) / GREATEST(a.price, b.price)
+			                  ELSE 1
+			              END AS score
+			FROM candidates c
+			JOIN active a ON a.id = c.product_id
+			JOIN active b ON b.id = c.related_id
+			LEFT JOIN co_purchases cp ON cp.product_id = c.product_id AND cp.related_id = c.related_id
+			LEFT JOIN product_orders pa ON pa.product_id = c.product_id
+			LEFT JOIN product_orders pb ON pb.product_id = c.related_id
+		),
+		ranked AS (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY score DESC, related_id) AS rn
+			FROM scored
+		)
+		INSERT INTO product_recommendations (product_id, related_product_id, score, reason, co_purchase_count, computed_at)
+		SELECT product_id, related_id, score,
+		       CASE WHEN together > 0 THEN 'co_purchase' ELSE 'category' END,
+		       together, NOW()
+		FROM ranked
+		WHERE rn <= $2
+	`
+	result, err := tx.ExecContext(ctx, query,
+		j.Lookback.Seconds(), j.PerProduct,
+		recommendationCoPurchaseWeight, recommendationCategoryWeight, recommendationPriceWeight,
+	)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	count, _ := result.RowsAffected()
+	log.Printf("Product recommendations refreshed: %d rows", count)
+	return nil
+}
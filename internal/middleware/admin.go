@@ -0,0 +1,42 @@
+// ========================================
+// internal/middleware/admin.go - YÖNETİCİ YETKİ KONTROLÜ
+// ========================================
+package middleware
+
+import (
+	"database/sql"
+	"ecommerce-backend/internal/database"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin Auth'tan sonra kullanılır; kullanıcının profiles.is_admin alanı true değilse
+// isteği 403 ile reddeder. Yetki her istekte veritabanından okunur, böylece yetkisi alınan
+// kullanıcının token'ı süresi dolana kadar yönetici işlemi yapamaz.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Abort()
+			return
+		}
+
+		var isAdmin bool
+		err := database.DB.QueryRow("SELECT COALESCE(is_admin, false) FROM profiles WHERE id = $1", userID).Scan(&isAdmin)
+		if err != nil && err != sql.ErrNoRows {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Yetki kontrol edilemedi: " + err.Error()})
+			c.Abort()
+			return
+		}
+		if !isAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yönetici yetkisi gerekli"})
+			c.Abort()
+			return
+		}
+
+		c.Set("isAdmin", true)
+		c.Next()
+	}
+}
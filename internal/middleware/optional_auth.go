@@ -0,0 +1,41 @@
+// ========================================
+// internal/middleware/optional_auth.go - OPSİYONEL KİMLİK DOĞRULAMA
+// ========================================
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OptionalAuth public endpoint'lerde geçerli bir token varsa kullanıcıyı tanır
+// (userID, userEmail); token yoksa veya geçersizse isteği reddetmeden devam eder.
+func OptionalAuth(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString == "" || tokenString == c.GetHeader("Authorization") {
+			c.Next()
+			return
+		}
+
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(jwtSecret), nil
+		})
+		if err == nil && token.Valid {
+			if claims, ok := token.Claims.(jwt.MapClaims); ok {
+				if sub, exists := claims["sub"]; exists {
+					c.Set("userID", sub)
+				}
+				if email, exists := claims["email"]; exists {
+					c.Set("userEmail", email)
+				}
+			}
+		}
+		c.Next()
+	}
+}
@@ -13,29 +13,51 @@ type User struct {
 }
 
 type Profile struct {
-	ID        string    `json:"id" db:"id"`
-	FullName  *string   `json:"full_name" db:"full_name"`
-	Username  *string   `json:"username" db:"username"`
-	Email     string    `json:"email" db:"email"`
-	IsAdmin   bool      `json:"is_admin" db:"is_admin"`
-	AvatarURL *string   `json:"avatar_url" db:"avatar_url"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID             string            `json:"id" db:"id"`
+	FullName       *string           `json:"full_name" db:"full_name"`
+	Username       *string           `json:"username" db:"username"`
+	Email          string            `json:"email" db:"email"`
+	IsAdmin        bool              `json:"is_admin" db:"is_admin"`
+	AvatarURL      *string           `json:"avatar_url" db:"avatar_url"`
+	AvatarVariants map[string]string `json:"avatar_variants,omitempty" db:"avatar_variants"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
 }
 
 type Product struct {
 	ID          int       `json:"id" db:"id"`
 	Title       string    `json:"title" db:"title"`
+	Slug        string    `json:"slug" db:"slug"`
 	Description string    `json:"description" db:"description"`
 	Price       float64   `json:"price" db:"price"`
 	Image       string    `json:"image" db:"image"`
 	Category    string    `json:"category" db:"category"`
+	CategoryID  *int      `json:"category_id" db:"category_id"`
 	SKU         string    `json:"sku" db:"sku"`
 	Rating      float64   `json:"rating" db:"rating"`
 	RatingCount int       `json:"rating_count" db:"rating_count"`
 	IsActive    bool      `json:"is_active" db:"is_active"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Satın alma limitleri (nil = limit yok)
+	MaxPerOrder             *int `json:"max_per_order,omitempty" db:"max_per_order"`
+	MaxPerCustomer          *int `json:"max_per_customer,omitempty" db:"max_per_customer"`
+	PurchaseLimitWindowDays *int `json:"purchase_limit_window_days,omitempty" db:"purchase_limit_window_days"`
+
+	// Arşivlenme zamanı (soft delete); nil ise ürün arşivde değil
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// İndirimli fiyat yalnızca sale_starts_at / sale_ends_at aralığında geçerlidir;
+	// compare_at_price indirim yokken de gösterilebilen "önceki fiyat"tır
+	CompareAtPrice *float64   `json:"compare_at_price,omitempty" db:"compare_at_price"`
+	SalePrice      *float64   `json:"sale_price,omitempty" db:"sale_price"`
+	SaleStartsAt   *time.Time `json:"sale_starts_at,omitempty" db:"sale_starts_at"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty" db:"sale_ends_at"`
+
+	// Şu an geçerli satış fiyatı ve (indirim varsa) üstü çizili gösterilecek fiyat
+	EffectivePrice float64  `json:"effective_price"`
+	OriginalPrice  *float64 `json:"original_price,omitempty"`
 }
 
 type Inventory struct {
@@ -51,9 +73,257 @@ type Inventory struct {
 
 type ProductWithStock struct {
 	Product
-	Inventory      *Inventory `json:"inventory"`
-	AvailableStock int        `json:"available_stock"`
-	StockStatus    string     `json:"stock_status"`
+	Inventory      *Inventory              `json:"inventory"`
+	AvailableStock int                     `json:"available_stock"`
+	StockStatus    string                  `json:"stock_status"`
+	Options        []ProductOption         `json:"options,omitempty"`
+	Variants       []ProductVariant        `json:"variants,omitempty"`
+	Images         []ProductImage          `json:"images,omitempty"`
+	Attributes     []ProductAttributeValue `json:"attributes,omitempty"`
+
+	// Yalnızca arama yapıldığında doldurulur
+	SearchRank *float64         `json:"search_rank,omitempty"`
+	Highlight  *SearchHighlight `json:"highlight,omitempty"`
+}
+
+// SearchHighlight eşleşen kelimeleri <mark> ile işaretlenmiş, HTML-escape edilmiş alıntılar
+type SearchHighlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// ProductFacets listeleme filtre paneli için sayımlar. Her boyut, kendi filtresi
+// hariç diğer tüm aktif filtrelere göre hesaplanır (çoklu seçim yapılabilsin diye).
+type ProductFacets struct {
+	Categories   []CategoryFacet  `json:"categories"`
+	PriceBuckets []PriceBucket    `json:"price_buckets"`
+	PriceRange   *PriceRange      `json:"price_range"`
+	StockStatus  []FacetValue     `json:"stock_status"`
+	Attributes   []AttributeFacet `json:"attributes"`
+}
+
+// CategoryFacet; Count alt kategorilerdeki ürünleri de kapsar
+type CategoryFacet struct {
+	ID       int    `json:"id"`
+	ParentID *int   `json:"parent_id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Count    int    `json:"count"`
+}
+
+// PriceBucket [Min, Max) aralığı; Max nil ise üst sınır yoktur
+type PriceBucket struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int      `json:"count"`
+}
+
+type PriceRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// AttributeFacet varyant seçeneği veya yapılandırılmış özellik sayımı. Code, Type ve Unit
+// yalnızca özellik tanımlarından gelen facet'lerde; Min/Max number tipinde doludur.
+type AttributeFacet struct {
+	Name   string       `json:"name"`
+	Code   string       `json:"code,omitempty"`
+	Type   string       `json:"type,omitempty"`
+	Unit   string       `json:"unit,omitempty"`
+	Min    *float64     `json:"min,omitempty"`
+	Max    *float64     `json:"max,omitempty"`
+	Values []FacetValue `json:"values"`
+}
+
+// Category hiyerarşik kategori ağacının bir düğümü. Children yalnızca ağaç
+// yanıtlarında doldurulur; ProductCount alt kategorilerdeki ürünleri de kapsar.
+type Category struct {
+	ID           int        `json:"id" db:"id"`
+	ParentID     *int       `json:"parent_id" db:"parent_id"`
+	Name         string     `json:"name" db:"name"`
+	Slug         string     `json:"slug" db:"slug"`
+	Description  string     `json:"description" db:"description"`
+	Image        string     `json:"image" db:"image"`
+	SortOrder    int        `json:"sort_order" db:"sort_order"`
+	IsActive     bool       `json:"is_active" db:"is_active"`
+	ProductCount int        `json:"product_count"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	Children     []Category `json:"children,omitempty"`
+}
+
+// AttributeDefinition tipli ürün özelliği tanımı (ör: Marka, Malzeme, Ağırlık [kg]).
+// Type: text, number, enum, boolean; Options yalnızca enum tipinde kullanılır.
+type AttributeDefinition struct {
+	ID           int       `json:"id" db:"id"`
+	Code         string    `json:"code" db:"code"`
+	Name         string    `json:"name" db:"name"`
+	Type         string    `json:"type" db:"type"`
+	Unit         string    `json:"unit" db:"unit"`
+	Options      []string  `json:"options,omitempty" db:"options"`
+	IsFilterable bool      `json:"is_filterable" db:"is_filterable"`
+	SortOrder    int       `json:"sort_order" db:"sort_order"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CategoryAttribute kategorideki ürünlerin kullanabileceği özellik. Üst kategoriye atanan
+// özellikler alt kategorilere de geçer; bunlarda InheritedFrom atamanın yapıldığı kategoridir.
+type CategoryAttribute struct {
+	AttributeDefinition
+	IsRequired    bool `json:"is_required"`
+	Position      int  `json:"position"`
+	InheritedFrom *int `json:"inherited_from,omitempty"`
+}
+
+// ProductAttributeValue ürünün özellik değeri; Value tipine göre string, float64 veya bool,
+// Display birimiyle birlikte gösterim metnidir (ör: "1.2 kg", "Evet")
+type ProductAttributeValue struct {
+	AttributeID int         `json:"attribute_id"`
+	Code        string      `json:"code"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Unit        string      `json:"unit,omitempty"`
+	Value       interface{} `json:"value"`
+	Display     string      `json:"display"`
+}
+
+// ProductImport toplu ürün içe aktarma işi. Status: pending, running, completed, failed.
+// DryRun işlerinde her batch doğrulanıp yazılır, ardından batch transaction'ı geri alınır.
+type ProductImport struct {
+	ID             int                     `json:"id" db:"id"`
+	UserID         string                  `json:"user_id" db:"user_id"`
+	Format         string                  `json:"format" db:"format"`
+	FileName       string                  `json:"file_name" db:"file_name"`
+	DryRun         bool                    `json:"dry_run" db:"dry_run"`
+	AllowBelowCost bool                    `json:"allow_below_cost" db:"allow_below_cost"`
+	Status         string                  `json:"status" db:"status"`
+	TotalRows      int                     `json:"total_rows" db:"total_rows"`
+	ProcessedRows  int                     `json:"processed_rows" db:"processed_rows"`
+	CreatedCount   int                     `json:"created_count" db:"created_count"`
+	UpdatedCount   int                     `json:"updated_count" db:"updated_count"`
+	FailedCount    int                     `json:"failed_count" db:"failed_count"`
+	Progress       float64                 `json:"progress"`
+	RowErrors      []ProductImportRowError `json:"row_errors,omitempty" db:"row_errors"`
+	ErrorMessage   *string                 `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt      time.Time               `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time              `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt     *time.Time              `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// ProductPriceChange ürün fiyat geçmişindeki bir kayıt
+type ProductPriceChange struct {
+	ID                int        `json:"id" db:"id"`
+	ProductID         int        `json:"product_id" db:"product_id"`
+	Price             float64    `json:"price" db:"price"`
+	CompareAtPrice    *float64   `json:"compare_at_price,omitempty" db:"compare_at_price"`
+	SalePrice         *float64   `json:"sale_price,omitempty" db:"sale_price"`
+	SaleStartsAt      *time.Time `json:"sale_starts_at,omitempty" db:"sale_starts_at"`
+	SaleEndsAt        *time.Time `json:"sale_ends_at,omitempty" db:"sale_ends_at"`
+	Source            string     `json:"source" db:"source"`
+	ChangedBy         *string    `json:"changed_by,omitempty" db:"changed_by"`
+	BelowCostOverride bool       `json:"below_cost_override" db:"below_cost_override"`
+	ChangedAt         time.Time  `json:"changed_at" db:"changed_at"`
+}
+
+// ProductImportRowError içe aktarılamayan satır; Row dosyadaki satır numarasıdır (CSV'de başlık 1. satır)
+type ProductImportRowError struct {
+	Row   int    `json:"row"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ProductImage ürün galerisindeki sıralı görsel. URL ana (large) varyanttır;
+// Variants thumbnail, medium, large ve webp URL'lerini içerir.
+type ProductImage struct {
+	ID         int               `json:"id" db:"id"`
+	ProductID  int               `json:"product_id" db:"product_id"`
+	ObjectPath string            `json:"-" db:"object_path"`
+	URL        string            `json:"url" db:"url"`
+	Variants   map[string]string `json:"variants,omitempty" db:"variants"`
+	AltText    string            `json:"alt_text" db:"alt_text"`
+	Position   int               `json:"position" db:"position"`
+	IsPrimary  bool              `json:"is_primary" db:"is_primary"`
+	CreatedAt  time.Time         `json:"created_at" db:"created_at"`
+}
+
+// ProductOption ürün seçenek tipi (ör: Beden, Renk)
+type ProductOption struct {
+	ID        int                  `json:"id" db:"id"`
+	ProductID int                  `json:"product_id" db:"product_id"`
+	Name      string               `json:"name" db:"name"`
+	Position  int                  `json:"position" db:"position"`
+	Values    []ProductOptionValue `json:"values"`
+}
+
+type ProductOptionValue struct {
+	ID       int    `json:"id" db:"id"`
+	OptionID int    `json:"option_id" db:"option_id"`
+	Value    string `json:"value" db:"value"`
+	Position int    `json:"position" db:"position"`
+}
+
+// ProductVariant kendi SKU, fiyat ve stoku olan ürün varyantı.
+// Price nil ise ürünün fiyatı geçerlidir (EffectivePrice).
+type ProductVariant struct {
+	ID             int               `json:"id" db:"id"`
+	ProductID      int               `json:"product_id" db:"product_id"`
+	SKU            string            `json:"sku" db:"sku"`
+	Price          *float64          `json:"price" db:"price"`
+	EffectivePrice float64           `json:"effective_price"`
+	Image          string            `json:"image" db:"image"`
+	IsActive       bool              `json:"is_active" db:"is_active"`
+	Options        map[string]string `json:"options"`
+	AvailableStock int               `json:"available_stock"`
+	StockStatus    string            `json:"stock_status"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// RelatedProduct ürün sayfasındaki "birlikte alınanlar / benzer ürünler" önerisi
+type RelatedProduct struct {
+	ProductID       int               `json:"product_id"`
+	Score           float64           `json:"score"`
+	Reason          string            `json:"reason"` // pinned, co_purchase, category
+	CoPurchaseCount int               `json:"co_purchase_count"`
+	Pinned          bool              `json:"pinned"`
+	Product         *ProductWithStock `json:"product"`
+}
+
+// ProductRecommendationOverride yöneticinin bir öneriyi sabitlemesi (pin) veya hariç tutması (exclude)
+type ProductRecommendationOverride struct {
+	ProductID        int       `json:"product_id" db:"product_id"`
+	RelatedProductID int       `json:"related_product_id" db:"related_product_id"`
+	RelatedTitle     string    `json:"related_title"`
+	Action           string    `json:"action" db:"action"`
+	Position         int       `json:"position" db:"position"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RecentlyViewedProduct kullanıcının son baktığı ürün
+type RecentlyViewedProduct struct {
+	ProductID int               `json:"product_id"`
+	ViewedAt  time.Time         `json:"viewed_at"`
+	Product   *ProductWithStock `json:"product"`
+}
+
+// TrendingProduct seçilen dönemde en çok görüntülenen ürün
+type TrendingProduct struct {
+	ProductID int               `json:"product_id"`
+	Views     int               `json:"views"`
+	Product   *ProductWithStock `json:"product"`
+}
+
+// ProductViewStat ürünün bir gündeki görüntülenme sayısı
+type ProductViewStat struct {
+	Day   string `json:"day"` // YYYY-MM-DD
+	Views int    `json:"views"`
 }
 
 type Cart struct {
@@ -64,12 +334,59 @@ type Cart struct {
 }
 
 type CartItem struct {
-	ID        int       `json:"id" db:"id"`
-	CartID    int       `json:"cart_id" db:"cart_id"`
-	ProductID int       `json:"product_id" db:"product_id"`
-	Quantity  int       `json:"quantity" db:"quantity"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	Product   *Product  `json:"product,omitempty"`
+	ID             int             `json:"id" db:"id"`
+	CartID         int             `json:"cart_id" db:"cart_id"`
+	ProductID      int             `json:"product_id" db:"product_id"`
+	VariantID      *int            `json:"variant_id,omitempty" db:"variant_id"`
+	Quantity       int             `json:"quantity" db:"quantity"`
+	PriceAtAdd     float64         `json:"price_at_add,omitempty" db:"price_at_add"`
+	UnitPrice      float64         `json:"unit_price,omitempty"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	Product        *Product        `json:"product,omitempty"`
+	Variant        *ProductVariant `json:"variant,omitempty"`
+	AvailableStock *int            `json:"available_stock,omitempty"`
+	HoldExpiresAt  *time.Time      `json:"hold_expires_at,omitempty"`
+	PriceChange    float64         `json:"price_change,omitempty"`
+	LineTotal      float64         `json:"line_total,omitempty"`
+	Warnings       []string        `json:"warnings,omitempty"`
+}
+
+// CartSummary sepet/sipariş tutar özeti
+type CartSummary struct {
+	ItemCount  int     `json:"item_count"`
+	Subtotal   float64 `json:"subtotal"`
+	Tax        float64 `json:"tax"`
+	Shipping   float64 `json:"shipping"`
+	GrandTotal float64 `json:"grand_total"`
+	Savings    float64 `json:"savings"`
+}
+
+// Sepet satırı uyarı kodları
+const (
+	CartWarningPriceIncreased = "PRICE_INCREASED"
+	CartWarningPriceDecreased = "PRICE_DECREASED"
+	CartWarningUnavailable    = "UNAVAILABLE"
+	CartWarningExceedsStock   = "EXCEEDS_STOCK"
+)
+
+type Wishlist struct {
+	ID         int            `json:"id" db:"id"`
+	UserID     string         `json:"user_id" db:"user_id"`
+	Name       string         `json:"name" db:"name"`
+	IsDefault  bool           `json:"is_default" db:"is_default"`
+	IsPublic   bool           `json:"is_public" db:"is_public"`
+	ShareToken *string        `json:"share_token,omitempty" db:"share_token"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at" db:"updated_at"`
+	Items      []WishlistItem `json:"items"`
+}
+
+type WishlistItem struct {
+	ID         int               `json:"id" db:"id"`
+	WishlistID int               `json:"wishlist_id" db:"wishlist_id"`
+	ProductID  int               `json:"product_id" db:"product_id"`
+	CreatedAt  time.Time         `json:"created_at" db:"created_at"`
+	Product    *ProductWithStock `json:"product,omitempty"`
 }
 
 type Comment struct {
@@ -106,6 +423,7 @@ type OrderItem struct {
 	ID         int     `json:"id" db:"id"`
 	OrderID    int     `json:"order_id" db:"order_id"`
 	ProductID  int     `json:"product_id" db:"product_id"`
+	VariantID  *int    `json:"variant_id,omitempty" db:"variant_id"`
 	Quantity   int     `json:"quantity" db:"quantity"`
 	UnitPrice  float64 `json:"unit_price" db:"unit_price"`
 	TotalPrice float64 `json:"total_price" db:"total_price"`
@@ -129,8 +447,143 @@ type AddCommentRequest struct {
 }
 
 type AddCartItemRequest struct {
+	ProductID int  `json:"product_id" binding:"required"`
+	VariantID *int `json:"variant_id"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
+}
+
+type SetCartItemRequest struct {
+	VariantID *int `json:"variant_id"`
+	Quantity  *int `json:"quantity" binding:"required,min=0"`
+}
+
+type BulkCartItemOperation struct {
+	ProductID int  `json:"product_id" binding:"required"`
+	VariantID *int `json:"variant_id"`
+	Quantity  *int `json:"quantity" binding:"required_without=Remove,omitempty,min=0"`
+	Remove    bool `json:"remove"`
+}
+
+type BulkUpdateCartRequest struct {
+	Items []BulkCartItemOperation `json:"items" binding:"required,min=1,dive"`
+}
+
+type CreateWishlistRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type AddWishlistItemRequest struct {
 	ProductID int `json:"product_id" binding:"required"`
-	Quantity  int `json:"quantity" binding:"required,min=1"`
+}
+
+type MoveToCartRequest struct {
+	VariantID *int `json:"variant_id"`
+	Quantity  int  `json:"quantity" binding:"omitempty,min=1"`
+}
+
+type SaveForLaterRequest struct {
+	WishlistID *int `json:"wishlist_id"`
+}
+
+type ShareWishlistRequest struct {
+	IsPublic bool `json:"is_public"`
+}
+
+type NotificationPreferencesRequest struct {
+	CartReminders *bool `json:"cart_reminders" binding:"required"`
+}
+
+type CreateProductOptionRequest struct {
+	Name     string   `json:"name" binding:"required,max=50"`
+	Position int      `json:"position"`
+	Values   []string `json:"values" binding:"required,min=1,dive,required"`
+}
+
+type CreateProductVariantRequest struct {
+	SKU           string            `json:"sku" binding:"required"`
+	Price         *float64          `json:"price" binding:"omitempty,gte=0"`
+	Image         string            `json:"image"`
+	IsActive      *bool             `json:"is_active"`
+	Options       map[string]string `json:"options" binding:"required"`
+	InitialStock  *int              `json:"initial_stock"`
+	MinStockLevel *int              `json:"min_stock_level"`
+}
+
+type UpdateProductVariantRequest struct {
+	SKU           *string  `json:"sku"`
+	Price         *float64 `json:"price" binding:"omitempty,gte=0"`
+	ClearPrice    bool     `json:"clear_price"`
+	Image         *string  `json:"image"`
+	IsActive      *bool    `json:"is_active"`
+	Stock         *int     `json:"stock" binding:"omitempty,gte=0"`
+	MinStockLevel *int     `json:"min_stock_level" binding:"omitempty,gte=0"`
+}
+
+type UpdateProductImageRequest struct {
+	AltText   *string `json:"alt_text" binding:"omitempty,max=255"`
+	IsPrimary *bool   `json:"is_primary"`
+}
+
+type ReorderProductImagesRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required,min=1"`
+}
+
+type CreateCategoryRequest struct {
+	ParentID    *int   `json:"parent_id"`
+	Name        string `json:"name" binding:"required,max=120"`
+	Slug        string `json:"slug" binding:"omitempty,max=140"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	SortOrder   int    `json:"sort_order"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+// UpdateCategoryRequest; MakeRoot true ise kategori köke taşınır (parent_id = NULL)
+type UpdateCategoryRequest struct {
+	ParentID    *int    `json:"parent_id"`
+	MakeRoot    bool    `json:"make_root"`
+	Name        *string `json:"name" binding:"omitempty,max=120"`
+	Slug        *string `json:"slug" binding:"omitempty,max=140"`
+	Description *string `json:"description"`
+	Image       *string `json:"image"`
+	SortOrder   *int    `json:"sort_order"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type CreateAttributeRequest struct {
+	Code         string   `json:"code" binding:"omitempty,max=60"`
+	Name         string   `json:"name" binding:"required,max=120"`
+	Type         string   `json:"type" binding:"required,oneof=text number enum boolean"`
+	Unit         string   `json:"unit" binding:"omitempty,max=20"`
+	Options      []string `json:"options" binding:"omitempty,dive,required,max=120"`
+	IsFilterable *bool    `json:"is_filterable"`
+	SortOrder    int      `json:"sort_order"`
+}
+
+// UpdateAttributeRequest; tip değiştirilemez (mevcut değerler geçersiz olur).
+// Options nil ise değişmez; ürünlerde kullanılan enum değeri listeden çıkarılamaz.
+type UpdateAttributeRequest struct {
+	Name         *string  `json:"name" binding:"omitempty,max=120"`
+	Unit         *string  `json:"unit" binding:"omitempty,max=20"`
+	Options      []string `json:"options" binding:"omitempty,dive,required,max=120"`
+	IsFilterable *bool    `json:"is_filterable"`
+	SortOrder    *int     `json:"sort_order"`
+}
+
+type CategoryAttributeAssignment struct {
+	AttributeID int  `json:"attribute_id" binding:"required"`
+	IsRequired  bool `json:"is_required"`
+	Position    int  `json:"position"`
+}
+
+// SetCategoryAttributesRequest kategoriye doğrudan atanan özellikleri tümüyle değiştirir
+type SetCategoryAttributesRequest struct {
+	Attributes []CategoryAttributeAssignment `json:"attributes" binding:"required,dive"`
+}
+
+type SetRecommendationOverrideRequest struct {
+	Action   string `json:"action" binding:"required,oneof=pin exclude"`
+	Position int    `json:"position"`
 }
 
 type CheckStockRequest struct {
@@ -0,0 +1,95 @@
+// ========================================
+// internal/notify/notify.go - BİLDİRİM KANALLARI
+// ========================================
+package notify
+
+import (
+	"bytes"
+	"context"
+	"ecommerce-backend/internal/config"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type CartReminderItem struct {
+	ProductID int     `json:"product_id"`
+	Title     string  `json:"title"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+type CartReminder struct {
+	CartID         int                `json:"cart_id"`
+	UserID         string             `json:"user_id"`
+	Email          string             `json:"email"`
+	ReminderNumber int                `json:"reminder_number"`
+	IdleSince      time.Time          `json:"idle_since"`
+	Items          []CartReminderItem `json:"items"`
+}
+
+// Notifier kullanıcıya bildirim gönderen kanal (e-posta servisi, webhook vb.)
+type Notifier interface {
+	SendCartReminder(ctx context.Context, reminder CartReminder) error
+}
+
+// New config'e göre bildirim kanalını seçer, bilinmeyen değerlerde log'a düşer
+func New(cfg *config.Config) Notifier {
+	switch cfg.Notifier {
+	case "webhook":
+		if cfg.NotifierWebhookURL == "" {
+			log.Println("NOTIFIER=webhook but NOTIFIER_WEBHOOK_URL is empty, falling back to log notifier")
+			return LogNotifier{}
+		}
+		return &WebhookNotifier{
+			URL:    cfg.NotifierWebhookURL,
+			Client: &http.Client{Timeout: 10 * time.Second},
+		}
+	default:
+		return LogNotifier{}
+	}
+}
+
+// LogNotifier bildirimleri sadece log'a yazar (development için)
+type LogNotifier struct{}
+
+func (LogNotifier) SendCartReminder(ctx context.Context, reminder CartReminder) error {
+	log.Printf("🛒 Cart reminder #%d -> user %s (%s), cart %d, %d item(s)",
+		reminder.ReminderNumber, reminder.UserID, reminder.Email, reminder.CartID, len(reminder.Items))
+	return nil
+}
+
+// WebhookNotifier bildirimi JSON olarak harici bir servise POST eder
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) SendCartReminder(ctx context.Context, reminder CartReminder) error {
+	body, err := json.Marshal(map[string]interface{}{"type": "cart_reminder", "data": reminder})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// ========================================
+// internal/storage/local.go - YEREL DOSYA SİSTEMİ BACKEND'İ
+// ========================================
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStorage nesneleri Root/{bucket}/{objectPath} altına yazar; dosyalar
+// main.go'daki router.Static ile BaseURL altından sunulur (geliştirme ve testler için)
+type LocalStorage struct {
+	Root    string
+	BaseURL string
+}
+
+// filePath bucket ve nesne yolunu Root altında kalacak şekilde dosya yoluna çevirir
+func (l *LocalStorage) filePath(bucket, objectPath string) (string, error) {
+	if bucket == "" || bucket == "." || bucket == ".." || strings.ContainsAny(bucket, `/\`) {
+		return "", ErrInvalidPath
+	}
+	// Baştaki "/" sayesinde ".." segmentleri bucket dışına çıkamaz
+	cleaned := path.Clean("/" + objectPath)
+	if cleaned == "/" {
+		return "", ErrInvalidPath
+	}
+	return filepath.Join(l.Root, bucket, filepath.FromSlash(cleaned)), nil
+}
+
+func (l *LocalStorage) Put(ctx context.Context, bucket, objectPath, contentType string, body io.Reader) error {
+	target, err := l.filePath(bucket, objectPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// Yarım yazılmış dosya sunulmasın diye önce geçici dosyaya yaz
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *LocalStorage) Delete(ctx context.Context, bucket, objectPath string) error {
+	target, err := l.filePath(bucket, objectPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *LocalStorage) PublicURL(bucket, objectPath string) string {
+	return strings.TrimRight(l.BaseURL, "/") + "/" + bucket + "/" + objectPath
+}
+
+// SignedURL yerel dosyalar statik olarak herkese açık sunulduğu için public URL'i döndürür
+func (l *LocalStorage) SignedURL(ctx context.Context, bucket, objectPath string, expiresIn time.Duration) (string, error) {
+	if _, err := l.filePath(bucket, objectPath); err != nil {
+		return "", err
+	}
+	return l.PublicURL(bucket, objectPath), nil
+}
@@ -0,0 +1,276 @@
+// ========================================
+// internal/storage/s3.go - S3 UYUMLU BACKEND (AWS S3, MinIO, R2...)
+// ========================================
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3MaxPresign      = 7 * 24 * time.Hour
+)
+
+// S3Storage AWS Signature V4 ile imzalanan isteklerle S3 uyumlu servislere yazar.
+// PathStyle true ise URL'ler endpoint/bucket/key, değilse bucket.endpoint/key biçimindedir.
+type S3Storage struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool
+	// PublicBaseURL doluysa public URL'ler {PublicBaseURL}/{bucket}/{key} olarak üretilir (CDN vb.)
+	PublicBaseURL string
+	Client        *http.Client
+}
+
+func (s *S3Storage) objectURL(bucket, objectPath string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimRight(s.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimLeft(objectPath, "/")
+	if s.PathStyle {
+		u.Path = "/" + bucket + "/" + key
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	// Gönderilen yol imzalanan canonical URI ile aynı olmalı; net/http'nin kendi kodlaması
+	// + = @ : ! $ ' ( ) * gibi karakterleri açık bıraktığından RawPath imza kodlamasıyla yazılır
+	u.RawPath = s3EscapePath(u.Path)
+	return u, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, bucket, objectPath, contentType string, body io.Reader) error {
+	// İmza için payload hash'i gerekir; görseller küçük olduğundan bellekte tutulur
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.objectURL(bucket, objectPath)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, sha256Hex(data), time.Now().UTC())
+
+	return s.send(req, "S3 yükleme hatası")
+}
+
+func (s *S3Storage) Delete(ctx context.Context, bucket, objectPath string) error {
+	u, err := s.objectURL(bucket, objectPath)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, sha256Hex(nil), time.Now().UTC())
+
+	// S3 olmayan nesne için de 204 döner
+	return s.send(req, "S3 silme hatası")
+}
+
+func (s *S3Storage) PublicURL(bucket, objectPath string) string {
+	if s.PublicBaseURL != "" {
+		return strings.TrimRight(s.PublicBaseURL, "/") + "/" + bucket + "/" + strings.TrimLeft(objectPath, "/")
+	}
+	u, err := s.objectURL(bucket, objectPath)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// SignedURL süreli (presigned) GET linki üretir; S3 en fazla 7 günlük süreye izin verir
+func (s *S3Storage) SignedURL(ctx context.Context, bucket, objectPath string, expiresIn time.Duration) (string, error) {
+	if expiresIn <= 0 || expiresIn > s3MaxPresign {
+		return "", fmt.Errorf("Geçersiz imzalı URL süresi: %s", expiresIn)
+	}
+	u, err := s.objectURL(bucket, objectPath)
+	if err != nil {
+		return "", err
+	}
+	return s.presign(u, expiresIn, time.Now().UTC()), nil
+}
+
+// presign u'ya imzalı sorgu parametrelerini ekler ve URL'i döner
+func (s *S3Storage) presign(u *url.URL, expiresIn time.Duration, now time.Time) string {
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiresIn.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		s3CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, amzDate, scope, canonicalRequest))
+	u.RawQuery = s3CanonicalQuery(query)
+	return u.String()
+}
+
+func (s *S3Storage) send(req *http.Request, errPrefix string) error {
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", errPrefix, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// sign isteğe Authorization başlığını ekler; host ve istekte set edilmiş tüm başlıklar
+// (x-amz-content-sha256, x-amz-date, content-type...) imzalanır
+func (s *S3Storage) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	// İstek satırındaki yol canonical URI ile aynı kodlanmalı (bkz. objectURL)
+	req.URL.RawPath = s3EscapePath(req.URL.Path)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.AccessKeyID, scope, signedHeaders, s.signature(now, amzDate, scope, canonicalRequest),
+	))
+}
+
+func (s *S3Storage) region() string {
+	if s.Region == "" {
+		return "us-east-1"
+	}
+	return s.Region
+}
+
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region() + "/s3/aws4_request"
+}
+
+func (s *S3Storage) signature(now time.Time, amzDate, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3Escape SigV4 kurallarına göre URI encode eder (yalnızca unreserved karakterler açık kalır)
+func s3Escape(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
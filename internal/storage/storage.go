@@ -0,0 +1,67 @@
+// ========================================
+// internal/storage/storage.go - NESNE DEPOLAMA ARAYÜZÜ
+// ========================================
+package storage
+
+import (
+	"context"
+	"ecommerce-backend/internal/config"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrInvalidPath = errors.New("Geçersiz nesne yolu")
+
+// Storage yüklenen dosyaların (avatar, ürün görselleri) saklandığı backend.
+// Nesneler bucket + bucket içi yol ile adreslenir; silme idempotenttir.
+type Storage interface {
+	Put(ctx context.Context, bucket, objectPath, contentType string, body io.Reader) error
+	Delete(ctx context.Context, bucket, objectPath string) error
+	PublicURL(bucket, objectPath string) string
+	SignedURL(ctx context.Context, bucket, objectPath string, expiresIn time.Duration) (string, error)
+}
+
+// New config'e göre storage backend'ini seçer. Varsayılan Supabase ayarları eksikse yerel
+// dosya sistemine düşer; böylece geliştirme ortamı Supabase olmadan çalışır. Açıkça
+// STORAGE_DRIVER=s3 seçilip ayarları eksikse veya sürücü adı tanınmıyorsa hata döner;
+// dosyalar sessizce sunucu diskine yazılmaz.
+func New(cfg *config.Config) (Storage, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	local := &LocalStorage{Root: cfg.LocalStorageDir, BaseURL: cfg.LocalStorageBaseURL}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.StorageDriver)) {
+	case "local":
+		return local, nil
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return nil, errors.New("STORAGE_DRIVER=s3 requires S3_ENDPOINT, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY")
+		}
+		return &S3Storage{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3UsePathStyle,
+			PublicBaseURL:   cfg.S3PublicURL,
+			Client:          client,
+		}, nil
+	case "", "supabase":
+		// Service role varsa onu kullan
+		authKey := cfg.SupabaseServiceKey
+		if authKey == "" {
+			authKey = cfg.SupabaseKey
+		}
+		if cfg.SupabaseURL == "" || authKey == "" {
+			log.Println("Supabase storage is not configured, falling back to local storage")
+			return local, nil
+		}
+		return &SupabaseStorage{URL: cfg.SupabaseURL, Key: authKey, Client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q (expected supabase, local or s3)", cfg.StorageDriver)
+	}
+}
@@ -0,0 +1,68 @@
+// ========================================
+// internal/storage/storage_test.go - BACKEND SEÇİMİ TESTLERİ
+// ========================================
+package storage
+
+import (
+	"ecommerce-backend/internal/config"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	s3 := func(cfg *config.Config) {
+		cfg.S3Endpoint, cfg.S3AccessKeyID, cfg.S3SecretAccessKey = "https://s3.example.com", "key", "secret"
+	}
+	supabase := func(cfg *config.Config) {
+		cfg.SupabaseURL, cfg.SupabaseKey = "https://project.supabase.co", "anon"
+	}
+
+	tests := []struct {
+		name    string
+		driver  string
+		setup   func(*config.Config)
+		want    string
+		wantErr bool
+	}{
+		{name: "tanımsız, supabase ayarsız", driver: "", want: "local"},
+		{name: "supabase ayarsız", driver: "supabase", want: "local"},
+		{name: "supabase", driver: "supabase", setup: supabase, want: "supabase"},
+		{name: "local", driver: "local", want: "local"},
+		{name: "büyük harf ve boşluk", driver: " S3 ", setup: s3, want: "s3"},
+		{name: "s3", driver: "s3", setup: s3, want: "s3"},
+		{name: "s3 eksik ayar", driver: "s3", wantErr: true},
+		{name: "bilinmeyen sürücü", driver: "s4", setup: s3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{StorageDriver: tt.driver}
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+
+			store, err := New(cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("hata bekleniyordu, %T döndü", store)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("beklenmeyen hata: %v", err)
+			}
+
+			var got string
+			switch store.(type) {
+			case *LocalStorage:
+				got = "local"
+			case *S3Storage:
+				got = "s3"
+			case *SupabaseStorage:
+				got = "supabase"
+			}
+			if got != tt.want {
+				t.Errorf("backend = %s, beklenen %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// ========================================
+// internal/storage/supabase.go - SUPABASE STORAGE BACKEND'İ
+// ========================================
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SupabaseStorage Supabase Storage REST API'si üzerinden çalışır
+type SupabaseStorage struct {
+	URL    string
+	Key    string
+	Client *http.Client
+}
+
+func (s *SupabaseStorage) endpoint(parts ...string) string {
+	return strings.TrimRight(s.URL, "/") + "/storage/v1/" + strings.Join(parts, "/")
+}
+
+func (s *SupabaseStorage) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+s.Key)
+	req.Header.Set("apikey", s.Key)
+	return s.Client.Do(req)
+}
+
+// Put dosyayı bucket/objectPath konumuna yükler (varsa üzerine yazar)
+func (s *SupabaseStorage) Put(ctx context.Context, bucket, objectPath, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint("object", bucket, objectPath)+"?upsert=true", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase yükleme hatası: %s", strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+func (s *SupabaseStorage) Delete(ctx context.Context, bucket, objectPath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.endpoint("object", bucket, objectPath), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Supabase silme hatası: %s", strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// PublicURL public bucket'taki nesnenin URL'ini döndürür
+func (s *SupabaseStorage) PublicURL(bucket, objectPath string) string {
+	return s.endpoint("object", "public", bucket, objectPath)
+}
+
+// SignedURL private bucket'lar için süreli erişim linki üretir
+func (s *SupabaseStorage) SignedURL(ctx context.Context, bucket, objectPath string, expiresIn time.Duration) (string, error) {
+	payload, _ := json.Marshal(map[string]int{"expiresIn": int(expiresIn.Seconds())})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint("object", "sign", bucket, objectPath), bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("Supabase imzalı URL hatası: %s", strings.TrimSpace(string(respBody)))
+	}
+
+	var signed struct {
+		SignedURL string `json:"signedURL"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&signed); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(s.URL, "/") + "/storage/v1" + signed.SignedURL, nil
+}
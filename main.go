@@ -4,10 +4,15 @@
 package main
 
 import (
+	"context"
 	"ecommerce-backend/internal/config"
 	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/feed"
 	"ecommerce-backend/internal/handlers"
+	"ecommerce-backend/internal/jobs"
 	"ecommerce-backend/internal/middleware"
+	"ecommerce-backend/internal/notify"
+	"ecommerce-backend/internal/storage"
 	"log"
 
 	"github.com/gin-gonic/gin"
@@ -22,6 +27,65 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Şema migration'larını uygula
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	// Önceki çalışmada yarıda kalan ürün içe aktarmaları
+	if err := handlers.FailInterruptedProductImports(); err != nil {
+		log.Printf("Interrupted product imports could not be marked as failed: %v", err)
+	}
+
+	// Arka plan job'ları
+	// Interval <= 0 iken time.NewTicker panic'ler; job yalnızca pozitif aralıkla başlatılır
+	if cfg.AbandonedCartEnabled && cfg.AbandonedCartCheckInterval > 0 {
+		abandonedCartJob := &jobs.AbandonedCartJob{
+			IdleAfter:    cfg.AbandonedCartIdleAfter,
+			Interval:     cfg.AbandonedCartCheckInterval,
+			MaxReminders: cfg.AbandonedCartMaxReminders,
+			Notifier:     notify.New(cfg),
+		}
+		abandonedCartJob.Start(context.Background())
+		log.Printf("🛒 Abandoned cart job started (idle after %s, every %s)", cfg.AbandonedCartIdleAfter, cfg.AbandonedCartCheckInterval)
+	}
+
+	if cfg.CartHoldsEnabled {
+		// Süresi dolan hold'lar stok hesaplarında zaten sayılmaz; temizlik yalnızca pozitif aralıkla çalışır
+		if cfg.CartHoldCleanupInterval > 0 {
+			cartHoldCleanupJob := &jobs.CartHoldCleanupJob{Interval: cfg.CartHoldCleanupInterval}
+			cartHoldCleanupJob.Start(context.Background())
+		}
+		log.Printf("⏳ Cart stock holds enabled (ttl %s)", cfg.CartHoldTTL)
+	}
+
+	// Ürün feed'leri; job kapalıysa (interval <= 0) ilk istekte üretilir
+	feedCache := feed.NewCache(feed.OptionsFromConfig(cfg))
+	if cfg.FeedRefreshInterval > 0 {
+		feedRefreshJob := &jobs.FeedRefreshJob{Interval: cfg.FeedRefreshInterval, Cache: feedCache}
+		feedRefreshJob.Start(context.Background())
+		log.Printf("📦 Product feed job started (every %s)", cfg.FeedRefreshInterval)
+	}
+
+	// İlgili ürün önerileri; endpoint yalnızca job'ın hesapladığı tablodan okur
+	if cfg.RecommendationsRefreshInterval > 0 {
+		recommendationJob := &jobs.RecommendationJob{
+			Interval:   cfg.RecommendationsRefreshInterval,
+			Lookback:   cfg.RecommendationsLookback,
+			PerProduct: cfg.RecommendationsPerProduct,
+		}
+		recommendationJob.Start(context.Background())
+		log.Printf("🔗 Product recommendation job started (every %s)", cfg.RecommendationsRefreshInterval)
+	}
+
+	if cfg.ProductViewCleanupInterval > 0 && cfg.ProductViewRetention > 0 {
+		productViewCleanupJob := &jobs.ProductViewCleanupJob{
+			Interval:  cfg.ProductViewCleanupInterval,
+			Retention: cfg.ProductViewRetention,
+		}
+		productViewCleanupJob.Start(context.Background())
+	}
+
 	// Gin mode set et
 	if cfg.Port == "8080" {
 		gin.SetMode(gin.DebugMode)
@@ -31,7 +95,8 @@ func main() {
 
 	// Gin router oluştur
 	router := gin.Default()
-	router.Static("/uploads", "./uploads")
+	// Yerel storage backend'inin dosyaları (STORAGE_DRIVER=local veya fallback)
+	router.Static(cfg.LocalStorageBaseURL, cfg.LocalStorageDir)
 
 	// Middleware'leri ekle
 	router.Use(middleware.CORS())
@@ -44,12 +109,22 @@ func main() {
 	// Recovery middleware
 	router.Use(gin.Recovery())
 
+	// Dosya depolama backend'i (supabase, local veya s3)
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatal("Failed to configure storage:", err)
+	}
+
 	// Handlers oluştur
 	authHandler := handlers.NewAuthHandler(cfg)
-	productHandler := handlers.NewProductHandler(cfg)
+	productHandler := handlers.NewProductHandler(cfg, store)
 	cartHandler := handlers.NewCartHandler(cfg)
 	commentHandler := handlers.NewCommentHandler(cfg)
-	profileHandler := handlers.NewProfileHandler(cfg)
+	profileHandler := handlers.NewProfileHandler(cfg, store)
+	wishlistHandler := handlers.NewWishlistHandler(cfg)
+	categoryHandler := handlers.NewCategoryHandler(cfg)
+	attributeHandler := handlers.NewAttributeHandler(cfg)
+	feedHandler := handlers.NewFeedHandler(cfg, feedCache)
 
 	// API routes
 	api := router.Group("/api/v1")
@@ -66,20 +141,84 @@ func main() {
 
 		// Product routes (public) - GÜNCELLENMIŞ
 		products := api.Group("/products")
+		// Katalog yönetimi yalnızca yöneticilere açıktır (profiles.is_admin)
+		productsAdmin := products.Group("", middleware.Auth(cfg.JWTSecret), middleware.RequireAdmin())
 		{
 			// Ana product endpoints
-			products.GET("", productHandler.GetProducts)            // /api/v1/products
-			products.GET("/:id", productHandler.GetProduct)         // /api/v1/products/123
-			products.GET("/count", productHandler.GetProductsCount) // /api/v1/products/count
+			products.GET("", productHandler.GetProducts)                                                            // /api/v1/products
+			products.GET("/:id", middleware.OptionalAuth(cfg.JWTSecret), productHandler.GetProduct)                 // /api/v1/products/123
+			products.GET("/count", productHandler.GetProductsCount)                                                 // /api/v1/products/count
+			products.GET("/by-slug/:slug", middleware.OptionalAuth(cfg.JWTSecret), productHandler.GetProductBySlug) // /api/v1/products/by-slug/kadin-elbise
+			products.GET("/trending", productHandler.GetTrendingProducts)                                           // /api/v1/products/trending?days=7
+			products.GET("/suggest", productHandler.SuggestProducts)                                                // /api/v1/products/suggest?q=ayakk
+			productsAdmin.GET("/admin", productHandler.GetAdminProducts)                                            // /api/v1/products/admin?status=archived
+
+			// Toplu içe aktarma (CSV / JSON lines)
+			productsAdmin.POST("/imports", productHandler.ImportProducts)            // POST /api/v1/products/imports?dry_run=true
+			productsAdmin.GET("/imports", productHandler.GetProductImports)          // GET /api/v1/products/imports
+			productsAdmin.GET("/imports/:importId", productHandler.GetProductImport) // GET /api/v1/products/imports/3
 
 			// Kategori ve stok endpoints
 			products.GET("/categories", productHandler.GetCategories)         // /api/v1/products/categories
 			products.GET("/low-stock-count", productHandler.GetLowStockCount) // /api/v1/products/low-stock-count
 
 			// CRUD + Stok kontrol endpoint
-			products.POST("", middleware.Auth(cfg.JWTSecret), productHandler.CreateProduct)    // POST /api/v1/products
-			products.PUT("/:id", middleware.Auth(cfg.JWTSecret), productHandler.UpdateProduct) // PUT /api/v1/products/:id
-			products.POST("/:id/check-stock", productHandler.CheckProductStock)                // /api/v1/products/123/check-stock
+			productsAdmin.POST("", productHandler.CreateProduct)                // POST /api/v1/products
+			productsAdmin.PUT("/:id", productHandler.UpdateProduct)             // PUT /api/v1/products/:id
+			productsAdmin.DELETE("/:id", productHandler.DeleteProduct)          // DELETE /api/v1/products/:id[?permanent=true]
+			productsAdmin.POST("/:id/restore", productHandler.RestoreProduct)   // POST /api/v1/products/:id/restore
+			products.POST("/:id/check-stock", productHandler.CheckProductStock) // /api/v1/products/123/check-stock
+
+			// Seçenek ve varyant yönetimi
+			productsAdmin.POST("/:id/options", productHandler.CreateProductOption)                // POST /api/v1/products/123/options
+			productsAdmin.POST("/:id/variants", productHandler.CreateProductVariant)              // POST /api/v1/products/123/variants
+			productsAdmin.PUT("/:id/variants/:variantId", productHandler.UpdateProductVariant)    // PUT /api/v1/products/123/variants/5
+			productsAdmin.DELETE("/:id/variants/:variantId", productHandler.DeleteProductVariant) // DELETE /api/v1/products/123/variants/5
+
+			// Görsel galerisi
+			products.GET("/:id/images", productHandler.GetProductImages)                    // GET /api/v1/products/123/images
+			productsAdmin.POST("/:id/images", productHandler.UploadProductImage)            // POST /api/v1/products/123/images (multipart)
+			productsAdmin.PATCH("/:id/images", productHandler.ReorderProductImages)         // PATCH /api/v1/products/123/images
+			productsAdmin.PUT("/:id/images/:imageId", productHandler.UpdateProductImage)    // PUT /api/v1/products/123/images/7
+			productsAdmin.DELETE("/:id/images/:imageId", productHandler.DeleteProductImage) // DELETE /api/v1/products/123/images/7
+
+			// İlgili ürünler (public) ve öneri sabitleme / hariç tutma (admin)
+			productsAdmin.GET("/:id/views", productHandler.GetProductViewStats)                                    // GET /api/v1/products/123/views?days=30
+			productsAdmin.GET("/:id/price-history", productHandler.GetProductPriceHistory)                         // GET /api/v1/products/123/price-history?limit=50
+			products.GET("/:id/related", productHandler.GetRelatedProducts)                                        // GET /api/v1/products/123/related?limit=8
+			productsAdmin.GET("/:id/related/overrides", productHandler.GetRecommendationOverrides)                 // GET /api/v1/products/123/related/overrides
+			productsAdmin.PUT("/:id/related/overrides/:relatedId", productHandler.SetRecommendationOverride)       // PUT /api/v1/products/123/related/overrides/45
+			productsAdmin.DELETE("/:id/related/overrides/:relatedId", productHandler.DeleteRecommendationOverride) // DELETE /api/v1/products/123/related/overrides/45
+		}
+
+		// Category routes - kategori ağacı (public) ve yönetimi (admin)
+		categories := api.Group("/categories")
+		categoriesAdmin := categories.Group("", middleware.Auth(cfg.JWTSecret), middleware.RequireAdmin())
+		{
+			categories.GET("", categoryHandler.GetCategoryTree)                           // GET /api/v1/categories
+			categories.GET("/:slug", categoryHandler.GetCategory)                         // GET /api/v1/categories/kadin-giyim
+			categoriesAdmin.POST("", categoryHandler.CreateCategory)                      // POST /api/v1/categories
+			categoriesAdmin.PUT("/:id", categoryHandler.UpdateCategory)                   // PUT /api/v1/categories/3
+			categoriesAdmin.DELETE("/:id", categoryHandler.DeleteCategory)                // DELETE /api/v1/categories/3
+			categoriesAdmin.PUT("/:id/attributes", categoryHandler.SetCategoryAttributes) // PUT /api/v1/categories/3/attributes
+		}
+
+		// Attribute routes - yapılandırılmış ürün özellikleri (tanım listesi public, yönetimi admin)
+		attributes := api.Group("/attributes")
+		attributesAdmin := attributes.Group("", middleware.Auth(cfg.JWTSecret), middleware.RequireAdmin())
+		{
+			attributes.GET("", attributeHandler.GetAttributes)               // GET /api/v1/attributes
+			attributesAdmin.POST("", attributeHandler.CreateAttribute)       // POST /api/v1/attributes
+			attributesAdmin.PUT("/:id", attributeHandler.UpdateAttribute)    // PUT /api/v1/attributes/4
+			attributesAdmin.DELETE("/:id", attributeHandler.DeleteAttribute) // DELETE /api/v1/attributes/4
+		}
+
+		// Feed routes - pazaryeri / reklam platformu feed'leri (public), yeniden üretim (admin)
+		feeds := api.Group("/feeds")
+		feedsAdmin := feeds.Group("", middleware.Auth(cfg.JWTSecret), middleware.RequireAdmin())
+		{
+			feeds.GET("/:file", feedHandler.GetFeed)              // GET /api/v1/feeds/google.xml
+			feedsAdmin.POST("/refresh", feedHandler.RefreshFeeds) // POST /api/v1/feeds/refresh
 		}
 
 		// Cart routes (protected)
@@ -87,9 +226,27 @@ func main() {
 		{
 			cart.GET("", cartHandler.GetCartItems)                                 // GET /api/v1/cart
 			cart.POST("/items", cartHandler.AddOrUpdateCartItem)                   // POST /api/v1/cart/items
+			cart.PATCH("/items", cartHandler.BulkUpdateCartItems)                  // PATCH /api/v1/cart/items
+			cart.PUT("/items/:productId", cartHandler.SetCartItemQuantity)         // PUT /api/v1/cart/items/123
 			cart.PUT("/items/:productId/decrement", cartHandler.DecrementCartItem) // PUT /api/v1/cart/items/123/decrement
 			cart.DELETE("/items/:productId", cartHandler.RemoveCartItem)           // DELETE /api/v1/cart/items/123
 			cart.POST("/checkout", cartHandler.CreateOrder)                        // POST /api/v1/cart/checkout
+
+			// Sepetten istek listesine taşı
+			cart.POST("/items/:productId/save-for-later", wishlistHandler.SaveForLater) // POST /api/v1/cart/items/123/save-for-later
+		}
+
+		// Wishlist routes (paylaşılan liste hariç protected)
+		api.GET("/wishlists/shared/:token", wishlistHandler.GetSharedWishlist) // GET /api/v1/wishlists/shared/abc123
+		wishlists := api.Group("/wishlists").Use(middleware.Auth(cfg.JWTSecret))
+		{
+			wishlists.GET("", wishlistHandler.GetWishlists)                                  // GET /api/v1/wishlists
+			wishlists.POST("", wishlistHandler.CreateWishlist)                               // POST /api/v1/wishlists
+			wishlists.DELETE("/:id", wishlistHandler.DeleteWishlist)                         // DELETE /api/v1/wishlists/5
+			wishlists.PUT("/:id/share", wishlistHandler.ShareWishlist)                       // PUT /api/v1/wishlists/5/share
+			wishlists.POST("/:id/items", wishlistHandler.AddWishlistItem)                    // POST /api/v1/wishlists/default/items
+			wishlists.DELETE("/:id/items/:productId", wishlistHandler.RemoveWishlistItem)    // DELETE /api/v1/wishlists/5/items/123
+			wishlists.POST("/:id/items/:productId/move-to-cart", wishlistHandler.MoveToCart) // POST /api/v1/wishlists/5/items/123/move-to-cart
 		}
 
 		// Comment routes (mixed access)
@@ -104,10 +261,16 @@ func main() {
 			profile.GET("", profileHandler.GetProfile)
 			profile.PUT("", profileHandler.UpdateProfile)
 			profile.POST("/avatar", profileHandler.UploadAvatar)
+			profile.PUT("/notifications", profileHandler.UpdateNotificationPreferences)
+			profile.GET("/recently-viewed", profileHandler.GetRecentlyViewed)
+			profile.DELETE("/recently-viewed", profileHandler.ClearRecentlyViewed)
 
 		}
 	}
 
+	// Arama motorları için sitemap (kök dizinde)
+	router.GET("/sitemap.xml", feedHandler.GetSitemap)
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -139,21 +302,79 @@ func main() {
 						"GET /auth/me":       "Get current user (protected)",
 					},
 					"products": gin.H{
-						"GET /products":                  "Get products with pagination & filters",
-						"GET /products/:id":              "Get single product by ID",
-						"GET /products/count":            "Get total products count",
-						"GET /products/categories":       "Get all categories",
-						"GET /products/low-stock-count":  "Get low stock products count",
-						"POST /products/:id/check-stock": "Check product stock availability",
-						"GET /products/supabase":         "Get products via Supabase client (testing)",
+						"GET /products":                                     "Get products with pagination & filters (full-text search ranked with highlights, fuzzy fallback with did_you_mean, category/category_id include subcategories, min_price/max_price/min_rating/categories/attr[Name or code] filters (number ranges like attr[ram]=8..16), facets, sort=relevance|newest|price_asc|price_desc|rating|name, cursor paging, real total); prices filter/sort by effective_price (active sale price), original_price shown while on sale",
+						"GET /products/:id":                                 "Get single product by ID (records a debounced view; X-Session-ID identifies guests)",
+						"GET /products/by-slug/:slug":                       "Get single product by slug (same response as GET /products/:id); old slugs 301-redirect to the current one",
+						"GET /products/trending":                            "Most viewed products (?days=7&limit=8&category_id=)",
+						"GET /products/:id/views":                           "Daily view counts for a product (?days=30, admin)",
+						"GET /products/:id/price-history":                   "Price, compare-at and sale price changes with source and user (?limit=50, admin)",
+						"GET /products/suggest":                             "Search-as-you-type suggestions (prefix + fuzzy over titles, categories, SKUs)",
+						"GET /products/admin":                               "Admin product listing incl. inactive/archived (?status=all|active|inactive|archived, admin)",
+						"DELETE /products/:id":                              "Archive product; ?permanent=true hard-deletes if never ordered (admin)",
+						"POST /products/:id/restore":                        "Restore archived product (admin)",
+						"POST /products/imports":                            "Bulk upsert products + inventory by SKU from CSV or JSON lines; async, ?dry_run=true, ?allow_below_cost=true accepts prices under cost_price (admin)",
+						"GET /products/imports":                             "List recent product imports (admin)",
+						"GET /products/imports/:importId":                   "Import progress and per-row error report (admin)",
+						"GET /products/count":                               "Get total products count",
+						"GET /products/categories":                          "Get all categories",
+						"GET /products/low-stock-count":                     "Get low stock products count",
+						"POST /products/:id/check-stock":                    "Check product stock availability",
+						"POST /products/:id/options":                        "Add product option with values (admin)",
+						"POST /products/:id/variants":                       "Create product variant (admin)",
+						"PUT /products/:id/variants/:variantId":             "Update product variant and stock (admin)",
+						"DELETE /products/:id/variants/:variantId":          "Deactivate product variant (admin)",
+						"GET /products/:id/images":                          "List product gallery images",
+						"POST /products/:id/images":                         "Upload product image (multipart, admin)",
+						"PATCH /products/:id/images":                        "Reorder product images (admin)",
+						"PUT /products/:id/images/:imageId":                 "Update alt text / primary image (admin)",
+						"DELETE /products/:id/images/:imageId":              "Delete product image and storage object (admin)",
+						"GET /products/:id/related":                         "Related products: pinned first, then co-purchase / category / price proximity score (?limit=8)",
+						"GET /products/:id/related/overrides":               "List pinned / excluded recommendations (admin)",
+						"PUT /products/:id/related/overrides/:relatedId":    "Pin or exclude a related product (admin)",
+						"DELETE /products/:id/related/overrides/:relatedId": "Remove recommendation override (admin)",
+						"GET /products/supabase":                            "Get products via Supabase client (testing)",
+					},
+					"categories": gin.H{
+						"GET /categories":                "Get category tree (?include_inactive=true)",
+						"GET /categories/:slug":          "Get category with children, breadcrumb and attributes",
+						"POST /categories":               "Create category (admin)",
+						"PUT /categories/:id":            "Update / move category (admin)",
+						"DELETE /categories/:id":         "Delete empty category (admin)",
+						"PUT /categories/:id/attributes": "Assign attributes to category, inherited by subcategories (admin)",
+					},
+					"attributes": gin.H{
+						"GET /attributes":        "List attribute definitions (text, number, enum, boolean)",
+						"POST /attributes":       "Create attribute definition (admin)",
+						"PUT /attributes/:id":    "Update attribute name, unit, options, filterability (admin)",
+						"DELETE /attributes/:id": "Delete attribute not used by any product (admin)",
+					},
+					"feeds": gin.H{
+						"GET /feeds/google.xml":   "Google Merchant Center RSS feed",
+						"GET /feeds/google.tsv":   "Google Merchant Center TSV feed",
+						"GET /feeds/catalog.json": "Generic JSON catalog feed",
+						"GET /sitemap.xml":        "Sitemap of home, active categories and products (served at site root, cached with feeds)",
+						"POST /feeds/refresh":     "Regenerate cached feeds now (admin)",
 					},
 					"cart": gin.H{
 						"GET /cart":                            "Get cart items (protected)",
 						"POST /cart/items":                     "Add/update cart item (protected)",
+						"PATCH /cart/items":                    "Bulk set/remove cart items (protected)",
+						"PUT /cart/items/:productId":           "Set cart item quantity (protected)",
 						"PUT /cart/items/:productId/decrement": "Decrement cart item quantity (protected)",
 						"DELETE /cart/items/:productId":        "Remove cart item (protected)",
 						"POST /cart/checkout":                  "Create order from cart (protected)",
 					},
+					"wishlists": gin.H{
+						"GET /wishlists":                                    "Get wishlists with items (protected)",
+						"POST /wishlists":                                   "Create named wishlist (protected)",
+						"DELETE /wishlists/:id":                             "Delete wishlist (protected)",
+						"PUT /wishlists/:id/share":                          "Enable/disable public share link (protected)",
+						"POST /wishlists/:id/items":                         "Add product to wishlist, :id may be 'default' (protected)",
+						"DELETE /wishlists/:id/items/:productId":            "Remove product from wishlist (protected)",
+						"POST /wishlists/:id/items/:productId/move-to-cart": "Move wishlist item to cart (protected)",
+						"POST /cart/items/:productId/save-for-later":        "Move cart item to wishlist (protected)",
+						"GET /wishlists/shared/:token":                      "Get public shared wishlist",
+					},
 					"comments": gin.H{
 						"GET /comments/product/:productId":      "Get product comments",
 						"POST /comments":                        "Add comment (protected)",